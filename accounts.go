@@ -2,7 +2,9 @@ package form3
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -12,6 +14,17 @@ const (
 
 type AccountsService service
 
+// Account operations of AccountsService, so consumers can depend on them
+// and substitute a fake such as form3mock.Accounts in tests.
+type AccountsAPI interface {
+	Create(ctx context.Context, data *Account, opts ...CallOption) (*Account, *Response, error)
+	ByID(ctx context.Context, id string, opts ...CallOption) (*Account, *Response, error)
+	List(ctx context.Context, options *AccountListOptions, opts ...CallOption) ([]*Account, *Response, error)
+	Delete(ctx context.Context, id string, version int, opts ...CallOption) (*Response, error)
+}
+
+var _ AccountsAPI = (*AccountsService)(nil)
+
 type Account struct {
 	ID             string                `json:"id"`
 	OrganisationID string                `json:"organisation_id"`
@@ -33,10 +46,10 @@ type VirtualAccount struct {
 }
 
 type AccountAttributes struct {
-	Country                    string                      `json:"country"`
-	BaseCurrency               string                      `json:"base_currency"`
+	Country                    Country                     `json:"country"`
+	BaseCurrency               Currency                    `json:"base_currency"`
 	BankID                     string                      `json:"bank_id"`
-	BankIDCode                 string                      `json:"bank_id_code"`
+	BankIDCode                 BankIDCode                  `json:"bank_id_code"`
 	AccountNumber              string                      `json:"account_number"`
 	BIC                        string                      `json:"bic"`
 	IBAN                       string                      `json:"iban"`
@@ -49,30 +62,30 @@ type AccountAttributes struct {
 type ConfirmationOfPayeeAttributes struct {
 	*AccountAttributes
 
-	AlternativeNames        []string `json:"alternative_names"`
-	AccountClassification   string   `json:"account_classification"`
-	JoinAccount             bool     `json:"join_account"`
-	AccountMatchingOptOut   bool     `json:"account_matching_opt_out"`
-	SecondaryIdentification string   `json:"secondary_identification"`
-	Switched                bool     `json:"switched"`
+	AlternativeNames        []string              `json:"alternative_names"`
+	AccountClassification   AccountClassification `json:"account_classification"`
+	JoinAccount             bool                  `json:"join_account"`
+	AccountMatchingOptOut   bool                  `json:"account_matching_opt_out"`
+	SecondaryIdentification string                `json:"secondary_identification"`
+	Switched                bool                  `json:"switched"`
 }
 
 type PrivateIdentification struct {
 	BirthDate      string   `json:"birth_date"`
-	BirthCountry   string   `json:"birth_country"`
+	BirthCountry   Country  `json:"birth_country"`
 	Identification string   `json:"identification"`
 	Address        []string `json:"address"`
-	Country        string   `json:"country"`
+	Country        Country  `json:"country"`
 	City           string   `json:"city"`
 }
 
 type OrganizationActor struct {
-	Name      string `json:"name"`
-	BirthDate string `json:"birth_date"`
-	Residency string `json:"residency"`
-	Address   string `json:"address"`
-	City      string `json:"city"`
-	Country   string `json:"country"`
+	Name      string  `json:"name"`
+	BirthDate string  `json:"birth_date"`
+	Residency string  `json:"residency"`
+	Address   string  `json:"address"`
+	City      string  `json:"city"`
+	Country   Country `json:"country"`
 }
 
 type OrganizationIdentification struct {
@@ -98,10 +111,63 @@ func MakeAccount(id, orgID string) *Account {
 	}
 }
 
+// Deep copy of the account, nil for nil.
+func (a *Account) Clone() *Account {
+	if a == nil {
+		return nil
+	}
+
+	clone := *a
+	if a.Attributes != nil {
+		attrs := *a.Attributes
+		if attrs.PrivateIdentification != nil {
+			private := *attrs.PrivateIdentification
+			private.Address = append([]string(nil), private.Address...)
+			attrs.PrivateIdentification = &private
+		}
+		if attrs.OrganizationIdentification != nil {
+			organization := *attrs.OrganizationIdentification
+			attrs.OrganizationIdentification = &organization
+		}
+		clone.Attributes = &attrs
+	}
+	if a.Relationships != nil {
+		clone.Relationships = &AccountRelationships{
+			MasterAccount: append([]MasterAccountRelation(nil), a.Relationships.MasterAccount...),
+		}
+	}
+	if a.CreatedOn != nil {
+		createdOn := *a.CreatedOn
+		clone.CreatedOn = &createdOn
+	}
+	if a.ModifiedOn != nil {
+		modifiedOn := *a.ModifiedOn
+		clone.ModifiedOn = &modifiedOn
+	}
+
+	return &clone
+}
+
 // Creates new account.
-func (s *AccountsService) Create(ctx context.Context, data *Account) (*Account, *Response, error) {
+func (s *AccountsService) Create(ctx context.Context, data *Account, opts ...CallOption) (*Account, *Response, error) {
+	if data == nil {
+		return nil, nil, ErrNilData
+	}
+
+	ctx = withOperation(ctx, "accounts.Create", opts...)
+	ctx = withResourceID(ctx, data.ID)
 	client := s.client
 
+	orgID, err := client.stampOrganisation(data.OrganisationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if orgID != data.OrganisationID {
+		stamped := *data
+		stamped.OrganisationID = orgID
+		data = &stamped
+	}
+
 	req, err := client.POST(accountsBaseEndpoint, data)
 	if err != nil {
 		return nil, nil, err
@@ -117,43 +183,76 @@ func (s *AccountsService) Create(ctx context.Context, data *Account) (*Account,
 }
 
 // Retrieves account by ID.
-func (s *AccountsService) ByID(ctx context.Context, id string) (*Account, *Response, error) {
+// With AccountsCache enabled, a fresh cached account is returned with a Cached *Response.
+func (s *AccountsService) ByID(ctx context.Context, id string, opts ...CallOption) (*Account, *Response, error) {
+	ctx = withOperation(ctx, "accounts.ByID", opts...)
+	ctx = withResourceID(ctx, id)
 	client := s.client
 	url := fmt.Sprintf("%s/%s", accountsBaseEndpoint, id)
 
 	req, err := client.GET(url, nil)
 	if err != nil {
-		return nil, nil, nil
+		return nil, nil, err
+	}
+
+	cache := client.accountsCache
+	var stale *accountCacheEntry
+	if cache != nil {
+		var cached *Account
+		if cached, stale = cache.lookup(id); cached != nil {
+			resp := newCachedResponse(req.WithContext(ctx))
+			if err := client.checkOrganisation(cached.OrganisationID); err != nil {
+				return nil, resp, err
+			}
+			return cached, resp, nil
+		}
+	}
+	if stale != nil {
+		stale.setValidators(req)
 	}
 
 	account := new(Account)
 	resp, err := client.Do(ctx, req, account)
 	if err != nil {
+		if cache != nil && IsNotFound(err) {
+			cache.remove(id)
+		}
+		return nil, resp, err
+	}
+
+	if cache != nil {
+		if stale != nil && resp.StatusCode == http.StatusNotModified {
+			account = cache.revalidated(stale)
+		} else {
+			cache.store(account, resp.Header)
+		}
+	}
+
+	if err := client.checkOrganisation(account.OrganisationID); err != nil {
 		return nil, resp, err
 	}
 
 	return account, resp, nil
 }
 
-// Specify pagination options.
-type AccountListOptions struct {
-	// Page number being requested: int or first|last
-	Number string
+// Statistics of the ByID cache, zero when AccountsCache is not enabled.
+func (s *AccountsService) CacheStats() CacheStats {
+	if s.client.accountsCache == nil {
+		return CacheStats{}
+	}
 
-	// Size of the page being requested
-	Size int
+	return s.client.accountsCache.snapshotStats()
 }
 
+// Specify pagination options.
+type AccountListOptions = ListOptions
+
 // Get list of accounts.
-func (s *AccountsService) List(ctx context.Context, options *AccountListOptions) ([]*Account, *Response, error) {
+func (s *AccountsService) List(ctx context.Context, options *AccountListOptions, opts ...CallOption) ([]*Account, *Response, error) {
+	ctx = withOperation(ctx, "accounts.List", opts...)
 	client := s.client
 
-	reqUrl := accountsBaseEndpoint
-	if options != nil {
-		reqUrl = fmt.Sprintf("%s?page[number]=%s&page[size]=%d", accountsBaseEndpoint, options.Number, options.Size)
-	}
-
-	req, err := client.GET(reqUrl, nil)
+	req, err := client.GET(addListOptions(accountsBaseEndpoint, client.scopeListOptions(options)), nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -164,17 +263,66 @@ func (s *AccountsService) List(ctx context.Context, options *AccountListOptions)
 		return nil, resp, err
 	}
 
+	for _, item := range *accounts {
+		if err := client.checkOrganisation(item.OrganisationID); err != nil {
+			return nil, resp, err
+		}
+	}
+
 	return *accounts, resp, nil
 }
 
+// Streams a page of accounts, calling fn for each account as it is decoded,
+// so the page is never held in memory. An error of fn stops the listing and is returned.
+func (s *AccountsService) ListEach(ctx context.Context, options *AccountListOptions, fn func(*Account) error, opts ...CallOption) (*Response, error) {
+	ctx = withOperation(ctx, "accounts.List", opts...)
+	client := s.client
+
+	req, err := client.GET(addListOptions(accountsBaseEndpoint, client.scopeListOptions(options)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	each := dataDecoder(func(dec *json.Decoder) error {
+		return decodeArray(dec, func(dec *json.Decoder) error {
+			account := new(Account)
+			if err := dec.Decode(account); err != nil {
+				return err
+			}
+			if err := client.checkCodes(account); err != nil {
+				return err
+			}
+			if err := client.checkOrganisation(account.OrganisationID); err != nil {
+				return err
+			}
+			return fn(account)
+		})
+	})
+
+	return client.Do(ctx, req, each)
+}
+
 // Delete account by id and version
-func (s *AccountsService) Delete(ctx context.Context, id string, version int) (*Response, error) {
+func (s *AccountsService) Delete(ctx context.Context, id string, version int, opts ...CallOption) (*Response, error) {
+	ctx = withOperation(ctx, "accounts.Delete", opts...)
+	ctx = withResourceID(ctx, id)
 	client := s.client
+
+	if err := client.CheckDestructive("accounts.Delete"); err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf("%s/%s?version=%d", accountsBaseEndpoint, id, version)
 
 	req, err := client.DELETE(url, nil)
 	if err != nil {
-		return nil, nil
+		return nil, err
+	}
+
+	// removed again once the request completes, a ByID running meanwhile may have stored
+	// the account again; also on errors, as the account may be deleted nevertheless
+	if cache := client.accountsCache; cache != nil {
+		cache.remove(id)
+		defer cache.remove(id)
 	}
 
 	resp, err := client.Do(ctx, req, nil)
@@ -2,12 +2,14 @@ package form3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func setupAccounts() (service *AccountsService, mux *http.ServeMux, serverURL string, teardown func()) {
@@ -21,6 +23,29 @@ func TestMakeAccount(t *testing.T) {
 	assert.Equal(t, "accounts", acc.Type)
 }
 
+func TestAccount_Clone(t *testing.T) {
+	now := time.Now()
+	account := MakeAccount("1", "2")
+	account.CreatedOn = &now
+	account.Attributes = &AccountAttributes{
+		Country:               CountryGB,
+		PrivateIdentification: &PrivateIdentification{Address: []string{"10 Downing St"}},
+	}
+	account.Relationships = &AccountRelationships{MasterAccount: []MasterAccountRelation{{ID: "3"}}}
+
+	clone := account.Clone()
+	assert.Equal(t, account, clone)
+
+	clone.Attributes.PrivateIdentification.Address[0] = "changed"
+	clone.Relationships.MasterAccount[0].ID = "changed"
+	*clone.CreatedOn = now.Add(time.Hour)
+	assert.Equal(t, "10 Downing St", account.Attributes.PrivateIdentification.Address[0])
+	assert.Equal(t, "3", account.Relationships.MasterAccount[0].ID)
+	assert.Equal(t, now, *account.CreatedOn)
+
+	assert.Nil(t, (*Account)(nil).Clone())
+}
+
 func TestAccountsService_Create(t *testing.T) {
 	service, mux, _, teardown := setupAccounts()
 	defer teardown()
@@ -87,6 +112,34 @@ func TestAccountsService_List(t *testing.T) {
 	}
 }
 
+func TestAccountsService_ListEach(t *testing.T) {
+	service, mux, _, teardown := setupAccounts()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"links":{"self":"/x"},"data":[{"id":"x-1"},{"id":"x-2"},{"id":"x-3"}]}`)
+	})
+
+	var ids []string
+	resp, err := service.ListEach(context.Background(), nil, func(account *Account) error {
+		ids = append(ids, account.ID)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"x-1", "x-2", "x-3"}, ids)
+
+	stop := errors.New("stop")
+	ids = nil
+	_, err = service.ListEach(context.Background(), nil, func(account *Account) error {
+		ids = append(ids, account.ID)
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"x-1"}, ids)
+}
+
 func TestAccountsService_ListWithOptions(t *testing.T) {
 	service, mux, _, teardown := setupAccounts()
 	defer teardown()
@@ -0,0 +1,247 @@
+// Package audit records the mutations made through a client as an append-only,
+// hash-chained NDJSON log, so edited, removed or reordered records can be detected.
+// Records cut off the end of the log are only noticed by comparing the count Verify returns.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Hash the first record is chained to.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Returned by Verify for logs that do not match their hash chain.
+var ErrTampered = errors.New("audit log tampered")
+
+// One request, a line of the log.
+type Record struct {
+	// Position in the log, from 1
+	Seq uint64 `json:"seq"`
+
+	Time           time.Time       `json:"time"`
+	Operation      string          `json:"operation"`
+	ResourceID     string          `json:"resource_id,omitempty"`
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Status         int             `json:"status"`
+	DurationMicros int64           `json:"duration_us"`
+	Error          string          `json:"error,omitempty"`
+
+	// Hash of the previous record, GenesisHash for the first one
+	PrevHash string `json:"prev_hash"`
+
+	// SHA-256 of the record encoded without Hash
+	Hash string `json:"hash"`
+}
+
+func (r *Record) computeHash() (string, error) {
+	unhashed := *r
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type Options struct {
+	// Records reads too, only mutations are recorded by default
+	IncludeReads bool
+
+	// Called when a record cannot be written
+	OnError func(error)
+
+	now func() time.Time
+}
+
+// Writes records of the requests it observes, see Sink.Hooks.
+type Sink struct {
+	options Options
+
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	seq      uint64
+	prevHash string
+	err      error
+}
+
+// Creates sink starting a new chain on w.
+func New(w io.Writer, options *Options) *Sink {
+	s := &Sink{w: w, prevHash: GenesisHash}
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.now == nil {
+		s.options.now = time.Now
+	}
+
+	return s
+}
+
+// Opens the log file for appending, continuing its chain after verifying it.
+func Open(path string, options *Options) (*Sink, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := verify(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("audit log %s: %w", path, err)
+	}
+
+	s := New(f, options)
+	s.closer = f
+	if last != nil {
+		s.seq = last.Seq
+		s.prevHash = last.Hash
+	}
+
+	return s, nil
+}
+
+// Hooks recording every request of the client, e.g. form3.WithHooks(sink.Hooks()).
+func (s *Sink) Hooks() *form3.Hooks {
+	return &form3.Hooks{AfterResponse: s.record}
+}
+
+func (s *Sink) record(ctx context.Context, info *form3.ResponseInfo) {
+	if !s.options.IncludeReads && (info.Method == http.MethodGet || info.Method == http.MethodHead) {
+		return
+	}
+
+	if err := s.Write(info); err != nil && s.options.OnError != nil {
+		s.options.OnError(err)
+	}
+}
+
+// Appends the record of the request to the log.
+func (s *Sink) Write(info *form3.ResponseInfo) error {
+	record := &Record{
+		Time:           s.options.now().UTC(),
+		Operation:      info.Operation,
+		ResourceID:     info.ResourceID,
+		Method:         info.Method,
+		URL:            info.URL,
+		Status:         info.StatusCode,
+		DurationMicros: info.Duration.Microseconds(),
+	}
+	if info.Err != nil {
+		record.Error = info.Err.Error()
+	}
+	if len(info.Payload) > 0 {
+		compact := &bytes.Buffer{}
+		if err := json.Compact(compact, info.Payload); err == nil {
+			record.Payload = compact.Bytes()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// a failed write may have left a partial line, the chain cannot continue
+	if s.err != nil {
+		return s.err
+	}
+
+	record.Seq = s.seq + 1
+	record.PrevHash = s.prevHash
+	hash, err := record.computeHash()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		s.err = fmt.Errorf("audit: %v", err)
+		return s.err
+	}
+
+	s.seq = record.Seq
+	s.prevHash = record.Hash
+	return nil
+}
+
+// First write error, after which no records are written.
+func (s *Sink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Closes the file of a sink created by Open.
+func (s *Sink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+// Checks the hash chain of the log, returning the number of records verified.
+// Errors of tampered logs wrap ErrTampered.
+func Verify(r io.Reader) (int, error) {
+	last, err := verify(r)
+	if last == nil {
+		return 0, err
+	}
+
+	return int(last.Seq), err
+}
+
+// Last record of the valid chain.
+func verify(r io.Reader) (*Record, error) {
+	var last *Record
+	prevHash := GenesisHash
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return last, fmt.Errorf("line %d: %w: %v", line, ErrTampered, err)
+		}
+
+		if record.Seq != uint64(line) {
+			return last, fmt.Errorf("line %d: %w: sequence %d", line, ErrTampered, record.Seq)
+		}
+		if record.PrevHash != prevHash {
+			return last, fmt.Errorf("line %d: %w: previous hash does not match", line, ErrTampered)
+		}
+		hash, err := record.computeHash()
+		if err != nil {
+			return last, err
+		}
+		if record.Hash != hash {
+			return last, fmt.Errorf("line %d: %w: hash does not match", line, ErrTampered)
+		}
+
+		prevHash = record.Hash
+		last = record
+	}
+
+	return last, scanner.Err()
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func response(method, id string, status int) *form3.ResponseInfo {
+	return &form3.ResponseInfo{
+		RequestInfo: form3.RequestInfo{
+			Operation:  "accounts.Delete",
+			ResourceID: id,
+			Method:     method,
+			URL:        "http://localhost:8080/v1/organisation/accounts/" + id,
+		},
+		StatusCode: status,
+		Duration:   1500 * time.Microsecond,
+	}
+}
+
+func TestSink(t *testing.T) {
+	log := &bytes.Buffer{}
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	sink := New(log, &Options{now: func() time.Time { return now }})
+
+	hooks := sink.Hooks()
+	hooks.AfterResponse(context.Background(), response("DELETE", "1", 204))
+	hooks.AfterResponse(context.Background(), response("GET", "1", 200))
+	info := response("POST", "2", 201)
+	info.Operation = "accounts.Create"
+	info.Payload = []byte(`{"data": {"id": "2"}}`)
+	info.Err = errors.New("x")
+	hooks.AfterResponse(context.Background(), info)
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, `{"seq":1,"time":"2020-05-01T10:00:00Z","operation":"accounts.Delete","resource_id":"1",`+
+		`"method":"DELETE","url":"http://localhost:8080/v1/organisation/accounts/1","status":204,"duration_us":1500,`+
+		`"prev_hash":"`+GenesisHash+`","hash":"`, lines[0][:strings.Index(lines[0], `"hash":"`)+8])
+	assert.Contains(t, lines[1], `"payload":{"data":{"id":"2"}}`)
+	assert.Contains(t, lines[1], `"error":"x"`)
+
+	n, err := Verify(strings.NewReader(log.String()))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestVerify_Tampered(t *testing.T) {
+	log := &bytes.Buffer{}
+	sink := New(log, nil)
+	for _, id := range []string{"1", "2", "3"} {
+		assert.Nil(t, sink.Write(response("DELETE", id, 204)))
+	}
+	lines := strings.SplitAfter(log.String(), "\n")
+
+	edited := strings.Replace(log.String(), `"status":204`, `"status":404`, 1)
+	n, err := Verify(strings.NewReader(edited))
+	assert.True(t, errors.Is(err, ErrTampered))
+	assert.EqualError(t, err, "line 1: audit log tampered: hash does not match")
+	assert.Equal(t, 0, n)
+
+	removed := lines[0] + lines[2]
+	_, err = Verify(strings.NewReader(removed))
+	assert.EqualError(t, err, "line 2: audit log tampered: sequence 3")
+
+	reordered := lines[1] + lines[0] + lines[2]
+	_, err = Verify(strings.NewReader(reordered))
+	assert.EqualError(t, err, "line 1: audit log tampered: sequence 2")
+
+	garbage := lines[0] + "{\n"
+	n, err = Verify(strings.NewReader(garbage))
+	assert.True(t, errors.Is(err, ErrTampered))
+	assert.Equal(t, 1, n)
+}
+
+func TestOpen(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "audit")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.ndjson")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		sink, err := Open(path, nil)
+		assert.Nil(t, err)
+
+		client, _ := form3.NewClient(form3.WithBaseURL(server.URL), form3.WithHooks(sink.Hooks()))
+		_, err = form3.CreateAccountsService(client).Delete(context.Background(), "1", 0)
+		assert.Nil(t, err)
+		assert.Nil(t, sink.Err())
+		assert.Nil(t, sink.Close())
+	}
+
+	f, _ := os.Open(path)
+	n, err := Verify(f)
+	_ = f.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+
+	data, _ := ioutil.ReadFile(path)
+	_ = ioutil.WriteFile(path, bytes.Replace(data, []byte(`"seq":2`), []byte(`"seq":3`), 1), 0600)
+	_, err = Open(path, nil)
+	assert.True(t, errors.Is(err, ErrTampered))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestSink_WriteError(t *testing.T) {
+	var reported error
+	sink := New(failingWriter{}, &Options{OnError: func(err error) { reported = err }})
+	sink.Hooks().AfterResponse(context.Background(), response("DELETE", "1", 204))
+
+	assert.EqualError(t, reported, "audit: disk full")
+	assert.Equal(t, reported, sink.Err())
+	assert.Equal(t, reported, sink.Write(response("DELETE", "2", 204)))
+}
@@ -0,0 +1,263 @@
+package form3
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Returned without sending the request while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// Requests failing with a transport error or a 5xx status count as failures.
+type CircuitBreakerOptions struct {
+	// Trips after this many failures in a row, 5 by default
+	ConsecutiveFailures int
+
+	// Trips when the failure rate within Window reaches it, e.g. 0.5; disabled when 0
+	FailureRate float64
+
+	// Requests within Window needed before FailureRate is applied, 10 by default
+	MinRequests int
+
+	// Rolling window of FailureRate, 1 minute by default
+	Window time.Duration
+
+	// How long the breaker stays open before letting a probe through, 30s by default
+	OpenTimeout time.Duration
+
+	// Breaker per request host instead of one for the client
+	PerHost bool
+
+	// Called on every state change, e.g. to raise an alert; not under the breaker lock,
+	// so it may use the client
+	OnStateChange func(host string, from, to CircuitState)
+}
+
+const windowBuckets = 10
+
+type windowBucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+type circuitBreaker struct {
+	host    string
+	options *CircuitBreakerOptions
+	now     func() time.Time
+
+	mu          sync.Mutex
+	state       CircuitState
+	consecutive int
+	openedAt    time.Time
+	probing     bool
+	buckets     [windowBuckets]windowBucket
+
+	// state changes made under mu, reported to OnStateChange once it is released
+	pending []transition
+}
+
+type transition struct {
+	from, to CircuitState
+}
+
+// Reports whether the request may be sent.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.options.OpenTimeout {
+			return false
+		}
+		b.setState(CircuitHalfOpen)
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		// a single probe at a time decides whether the breaker closes
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+
+	return true
+}
+
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := b.now()
+	bucketSize := b.options.Window / windowBuckets
+	bucket := &b.buckets[now.UnixNano()/int64(bucketSize)%windowBuckets]
+	if now.Sub(bucket.start) >= bucketSize {
+		*bucket = windowBucket{start: now.Truncate(bucketSize)}
+	}
+
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+		if failed {
+			b.trip(now)
+		} else {
+			b.reset()
+		}
+		return
+	}
+
+	if !failed {
+		bucket.successes++
+		b.consecutive = 0
+		return
+	}
+
+	bucket.failures++
+	b.consecutive++
+	if b.consecutive >= b.options.ConsecutiveFailures || b.failureRateExceeded(now) {
+		b.trip(now)
+	}
+}
+
+func (b *circuitBreaker) failureRateExceeded(now time.Time) bool {
+	if b.options.FailureRate <= 0 {
+		return false
+	}
+
+	total, failures := 0, 0
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) < b.options.Window {
+			total += bucket.successes + bucket.failures
+			failures += bucket.failures
+		}
+	}
+
+	return total >= b.options.MinRequests && float64(failures)/float64(total) >= b.options.FailureRate
+}
+
+func (b *circuitBreaker) trip(now time.Time) {
+	b.openedAt = now
+	b.setState(CircuitOpen)
+}
+
+func (b *circuitBreaker) reset() {
+	b.consecutive = 0
+	b.buckets = [windowBuckets]windowBucket{}
+	b.setState(CircuitClosed)
+}
+
+func (b *circuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+
+	if b.options.OnStateChange != nil {
+		b.pending = append(b.pending, transition{from: b.state, to: state})
+	}
+	b.state = state
+}
+
+// Releases mu, then reports pending state changes, so OnStateChange may call back into the client.
+func (b *circuitBreaker) unlock() {
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	for _, t := range pending {
+		b.options.OnStateChange(b.host, t.from, t.to)
+	}
+}
+
+type circuitBreakerTransport struct {
+	next    http.RoundTripper
+	options *CircuitBreakerOptions
+	now     func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newCircuitBreakerTransport(next http.RoundTripper, options *CircuitBreakerOptions) *circuitBreakerTransport {
+	opts := *options
+	if opts.ConsecutiveFailures <= 0 {
+		opts.ConsecutiveFailures = 5
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = 10
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+
+	return &circuitBreakerTransport{
+		next:     next,
+		options:  &opts,
+		now:      time.Now,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+func (t *circuitBreakerTransport) breaker(host string) *circuitBreaker {
+	if !t.options.PerHost {
+		host = ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &circuitBreaker{host: host, options: t.options, now: t.now}
+		t.breakers[host] = b
+	}
+
+	return b
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.breaker(req.URL.Host)
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	// requests cancelled by the caller tell nothing about the API
+	if err != nil && req.Context().Err() != nil {
+		b.mu.Lock()
+		if b.state == CircuitHalfOpen {
+			b.probing = false
+		}
+		b.mu.Unlock()
+		return nil, err
+	}
+
+	b.record(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
@@ -0,0 +1,180 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Responds with the status set for the host, counting requests.
+type stubTransport struct {
+	status   map[string]int
+	requests int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests++
+	status := s.status[req.URL.Host]
+	if status == 0 {
+		return nil, errors.New("connection refused")
+	}
+
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+}
+
+type stateChange struct {
+	host     string
+	from, to CircuitState
+}
+
+func setupBreaker(options *CircuitBreakerOptions) (*circuitBreakerTransport, *stubTransport, *time.Time, *[]stateChange) {
+	stub := &stubTransport{status: map[string]int{"a": 200, "b": 200}}
+	changes := &[]stateChange{}
+	options.OnStateChange = func(host string, from, to CircuitState) {
+		*changes = append(*changes, stateChange{host, from, to})
+	}
+
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	transport := newCircuitBreakerTransport(stub, options)
+	transport.now = func() time.Time { return now }
+
+	return transport, stub, &now, changes
+}
+
+func send(transport http.RoundTripper, host string) error {
+	req, _ := http.NewRequest("GET", "http://"+host+"/x", nil)
+	_, err := transport.RoundTrip(req)
+	return err
+}
+
+func TestCircuitBreaker_TripsOnConsecutiveFailures(t *testing.T) {
+	transport, stub, clock, changes := setupBreaker(&CircuitBreakerOptions{ConsecutiveFailures: 3, OpenTimeout: time.Second})
+	stub.status["a"] = 503
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, send(transport, "a"))
+	}
+	assert.Equal(t, ErrCircuitOpen, send(transport, "a"))
+	assert.Equal(t, ErrCircuitOpen, send(transport, "b"), "breaker is shared between hosts")
+	assert.Equal(t, 3, stub.requests)
+
+	// probe fails, breaker opens again
+	*clock = clock.Add(time.Second)
+	assert.Nil(t, send(transport, "a"))
+	assert.Equal(t, ErrCircuitOpen, send(transport, "a"))
+
+	// probe succeeds, breaker closes
+	stub.status["a"] = 200
+	*clock = clock.Add(time.Second)
+	assert.Nil(t, send(transport, "a"))
+	assert.Nil(t, send(transport, "a"))
+
+	assert.Equal(t, []stateChange{
+		{"", CircuitClosed, CircuitOpen},
+		{"", CircuitOpen, CircuitHalfOpen},
+		{"", CircuitHalfOpen, CircuitOpen},
+		{"", CircuitOpen, CircuitHalfOpen},
+		{"", CircuitHalfOpen, CircuitClosed},
+	}, *changes)
+}
+
+func TestCircuitBreaker_SuccessResetsConsecutiveFailures(t *testing.T) {
+	transport, stub, _, _ := setupBreaker(&CircuitBreakerOptions{ConsecutiveFailures: 2})
+
+	for i := 0; i < 5; i++ {
+		stub.status["a"] = 500
+		assert.Nil(t, send(transport, "a"))
+		stub.status["a"] = 200
+		assert.Nil(t, send(transport, "a"))
+	}
+}
+
+func TestCircuitBreaker_TripsOnFailureRate(t *testing.T) {
+	transport, stub, clock, _ := setupBreaker(&CircuitBreakerOptions{
+		ConsecutiveFailures: 100,
+		FailureRate:         0.5,
+		MinRequests:         4,
+		Window:              10 * time.Second,
+	})
+
+	// failures that fell out of the window do not count
+	stub.status["a"] = 500
+	assert.Nil(t, send(transport, "a"))
+	assert.Nil(t, send(transport, "a"))
+	*clock = clock.Add(20 * time.Second)
+
+	stub.status["a"] = 200
+	assert.Nil(t, send(transport, "a"))
+	assert.Nil(t, send(transport, "a"))
+	stub.status["a"] = 0
+	assert.NotNil(t, send(transport, "a"))
+	assert.Equal(t, "connection refused", send(transport, "a").Error(), "rate is not reached before 4 requests")
+	assert.Equal(t, ErrCircuitOpen, send(transport, "a"))
+}
+
+func TestCircuitBreaker_PerHost(t *testing.T) {
+	transport, stub, _, changes := setupBreaker(&CircuitBreakerOptions{ConsecutiveFailures: 1, PerHost: true})
+	stub.status["a"] = 500
+
+	assert.Nil(t, send(transport, "a"))
+	assert.Equal(t, ErrCircuitOpen, send(transport, "a"))
+	assert.Nil(t, send(transport, "b"))
+	assert.Equal(t, []stateChange{{"a", CircuitClosed, CircuitOpen}}, *changes)
+}
+
+func TestCircuitBreaker_IgnoresCancelledRequests(t *testing.T) {
+	transport, stub, _, _ := setupBreaker(&CircuitBreakerOptions{ConsecutiveFailures: 1})
+	stub.status["a"] = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequest("GET", "http://a/x", nil)
+	_, err := transport.RoundTrip(req.WithContext(ctx))
+	assert.NotNil(t, err)
+	assert.NotEqual(t, ErrCircuitOpen, send(transport, "a"))
+}
+
+func TestClient_DoCircuitOpen(t *testing.T) {
+	client := CreateClient(&ClientOptions{
+		Timeout:        3000,
+		BaseEndpoint:   "http://localhost:1/v1",
+		CircuitBreaker: &CircuitBreakerOptions{ConsecutiveFailures: 1},
+	})
+
+	req, _ := client.GET("/x", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	req, _ = client.GET("/x", nil)
+	_, err = client.Do(context.Background(), req, nil)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+}
+
+func TestCircuitBreaker_OnStateChangeMayUseTransport(t *testing.T) {
+	var transport *circuitBreakerTransport
+	reentered := make(chan error, 1)
+	transport = newCircuitBreakerTransport(&stubTransport{status: map[string]int{"a": 500}}, &CircuitBreakerOptions{
+		ConsecutiveFailures: 1,
+		OnStateChange: func(host string, from, to CircuitState) {
+			reentered <- send(transport, host)
+		},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		_ = send(transport, "a")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		assert.Equal(t, ErrCircuitOpen, <-reentered)
+	case <-time.After(time.Second):
+		t.Fatal("OnStateChange deadlocked")
+	}
+}
@@ -0,0 +1,272 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bank details used by a country.
+type CountryPreset struct {
+	BankIDCode BankIDCode
+	Currency   Currency
+
+	// Digits of the bank_id, 0 when the country does not use one
+	BankIDLength int
+
+	// Digits of a generated account number
+	AccountNumberLength int
+
+	// Whether the account must have a BIC
+	BICRequired bool
+}
+
+// Presets of the countries with a supported bank ID code.
+var CountryPresets = map[Country]CountryPreset{
+	CountryAU: {BankIDCode: BankIDCodeAUBSB, Currency: CurrencyAUD, BankIDLength: 6, AccountNumberLength: 9, BICRequired: true},
+	CountryBE: {BankIDCode: BankIDCodeBE, Currency: CurrencyEUR, BankIDLength: 3, AccountNumberLength: 7},
+	CountryCA: {BankIDCode: BankIDCodeCACPA, Currency: CurrencyCAD, BankIDLength: 9, AccountNumberLength: 12},
+	CountryCH: {BankIDCode: BankIDCodeCHBCC, Currency: CurrencyCHF, BankIDLength: 5, AccountNumberLength: 12},
+	CountryDE: {BankIDCode: BankIDCodeDEBLZ, Currency: CurrencyEUR, BankIDLength: 8, AccountNumberLength: 7},
+	CountryES: {BankIDCode: BankIDCodeESNCC, Currency: CurrencyEUR, BankIDLength: 8, AccountNumberLength: 10},
+	CountryFR: {BankIDCode: BankIDCodeFR, Currency: CurrencyEUR, BankIDLength: 10, AccountNumberLength: 10},
+	CountryGB: {BankIDCode: BankIDCodeGBDSC, Currency: CurrencyGBP, BankIDLength: 6, AccountNumberLength: 8, BICRequired: true},
+	CountryGR: {BankIDCode: BankIDCodeGRBIC, Currency: CurrencyEUR, BankIDLength: 7, AccountNumberLength: 16},
+	CountryHK: {BankIDCode: BankIDCodeHKNCC, Currency: CurrencyHKD, BankIDLength: 3, AccountNumberLength: 9},
+	CountryIT: {BankIDCode: BankIDCodeITNCC, Currency: CurrencyEUR, BankIDLength: 10, AccountNumberLength: 12},
+	CountryLU: {BankIDCode: BankIDCodeLUNCC, Currency: CurrencyEUR, BankIDLength: 3, AccountNumberLength: 13},
+	CountryNL: {BankIDCode: BankIDCodeNLBIC, Currency: CurrencyEUR, AccountNumberLength: 10, BICRequired: true},
+	CountryPL: {BankIDCode: BankIDCodePLKNR, Currency: CurrencyPLN, BankIDLength: 8, AccountNumberLength: 16},
+	CountryPT: {BankIDCode: BankIDCodePTNCC, Currency: CurrencyEUR, BankIDLength: 8, AccountNumberLength: 11},
+	CountryUS: {BankIDCode: BankIDCodeUSABA, Currency: CurrencyUSD, BankIDLength: 9, AccountNumberLength: 10, BICRequired: true},
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	bicPattern  = regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)
+)
+
+// Builds accounts field by field, checking them at Build.
+// The ID is generated unless set.
+type AccountBuilder struct {
+	account Account
+	attrs   AccountAttributes
+}
+
+func NewAccountBuilder() *AccountBuilder {
+	return &AccountBuilder{account: *MakeAccount(CreateUUID(), "")}
+}
+
+func (b *AccountBuilder) ID(id string) *AccountBuilder {
+	b.account.ID = id
+	return b
+}
+
+func (b *AccountBuilder) OrganisationID(orgID string) *AccountBuilder {
+	b.account.OrganisationID = orgID
+	return b
+}
+
+// Sets the country with the bank ID code and currency of its preset, if there is one.
+func (b *AccountBuilder) Country(country Country) *AccountBuilder {
+	b.attrs.Country = country
+	if preset, ok := CountryPresets[country]; ok {
+		b.attrs.BankIDCode = preset.BankIDCode
+		b.attrs.BaseCurrency = preset.Currency
+	}
+	return b
+}
+
+func (b *AccountBuilder) BaseCurrency(currency Currency) *AccountBuilder {
+	b.attrs.BaseCurrency = currency
+	return b
+}
+
+func (b *AccountBuilder) BankID(bankID string) *AccountBuilder {
+	b.attrs.BankID = bankID
+	return b
+}
+
+func (b *AccountBuilder) BankIDCode(code BankIDCode) *AccountBuilder {
+	b.attrs.BankIDCode = code
+	return b
+}
+
+func (b *AccountBuilder) AccountNumber(number string) *AccountBuilder {
+	b.attrs.AccountNumber = number
+	return b
+}
+
+func (b *AccountBuilder) BIC(bic string) *AccountBuilder {
+	b.attrs.BIC = bic
+	return b
+}
+
+func (b *AccountBuilder) IBAN(iban string) *AccountBuilder {
+	b.attrs.IBAN = iban
+	return b
+}
+
+func (b *AccountBuilder) CustomerID(customerID string) *AccountBuilder {
+	b.attrs.CustomerID = customerID
+	return b
+}
+
+func (b *AccountBuilder) Name(name string) *AccountBuilder {
+	b.attrs.Name = name
+	return b
+}
+
+var (
+	randomMu sync.Mutex
+	random   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randomDigits(n int) string {
+	randomMu.Lock()
+	defer randomMu.Unlock()
+
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + random.Intn(10))
+	}
+
+	return string(digits)
+}
+
+func randomLetters(n int) string {
+	randomMu.Lock()
+	defer randomMu.Unlock()
+
+	letters := make([]byte, n)
+	for i := range letters {
+		letters[i] = byte('A' + random.Intn(26))
+	}
+
+	return string(letters)
+}
+
+// Fills the empty bank details of the country preset with random valid values,
+// for test fixtures. GB is used when no country with a preset is set.
+func (b *AccountBuilder) Random() *AccountBuilder {
+	if _, ok := CountryPresets[b.attrs.Country]; !ok {
+		b.Country(CountryGB)
+	}
+	preset := CountryPresets[b.attrs.Country]
+
+	if b.account.OrganisationID == "" {
+		b.account.OrganisationID = CreateUUID()
+	}
+	if b.attrs.BankID == "" && preset.BankIDLength > 0 {
+		b.attrs.BankID = randomDigits(preset.BankIDLength)
+	}
+	if b.attrs.AccountNumber == "" {
+		b.attrs.AccountNumber = randomDigits(preset.AccountNumberLength)
+	}
+	if b.attrs.BIC == "" {
+		b.attrs.BIC = randomLetters(4) + string(b.attrs.Country) + randomLetters(2)
+	}
+	if b.attrs.CustomerID == "" {
+		b.attrs.CustomerID = "CUST-" + randomDigits(6)
+	}
+	if b.attrs.Name == "" {
+		b.attrs.Name = "Test " + randomLetters(6)
+	}
+	return b
+}
+
+// Copy of the built account, or the first problem found.
+func (b *AccountBuilder) Build() (*Account, error) {
+	account := b.account
+	attrs := b.attrs
+	account.Attributes = &attrs
+
+	if err := ValidateAccount(&account); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// Like Build but panics on invalid accounts, for fixtures.
+func (b *AccountBuilder) MustBuild() *Account {
+	account, err := b.Build()
+	if err != nil {
+		panic(fmt.Sprintf("form3: %v", err))
+	}
+
+	return account
+}
+
+// Random valid account of the country, see AccountBuilder.Random.
+func RandomAccount(country Country) *Account {
+	return NewAccountBuilder().Country(country).Random().MustBuild()
+}
+
+// Checks the account against the rules of the API and the country preset.
+func ValidateAccount(account *Account) error {
+	var problems []string
+	if !uuidPattern.MatchString(account.ID) {
+		problems = append(problems, fmt.Sprintf("id must be a UUID: %q", account.ID))
+	}
+	if !uuidPattern.MatchString(account.OrganisationID) {
+		problems = append(problems, fmt.Sprintf("organisation_id must be a UUID: %q", account.OrganisationID))
+	}
+
+	attrs := account.Attributes
+	if attrs == nil {
+		return errors.New(strings.Join(append(problems, "attributes are required"), "; "))
+	}
+
+	if !attrs.Country.Valid() {
+		problems = append(problems, fmt.Sprintf("country is not an ISO 3166 code: %q", attrs.Country))
+	}
+	if attrs.BaseCurrency != "" && !attrs.BaseCurrency.Valid() {
+		problems = append(problems, fmt.Sprintf("base_currency is not an ISO 4217 code: %q", attrs.BaseCurrency))
+	}
+	if attrs.BankIDCode != "" {
+		if !attrs.BankIDCode.Valid() {
+			problems = append(problems, fmt.Sprintf("bank_id_code is not supported: %q", attrs.BankIDCode))
+		} else if attrs.BankIDCode.Country() != attrs.Country {
+			problems = append(problems, fmt.Sprintf("bank_id_code %s is not used in %s", attrs.BankIDCode, attrs.Country))
+		}
+	}
+	if attrs.BIC != "" && !bicPattern.MatchString(attrs.BIC) {
+		problems = append(problems, fmt.Sprintf("bic is not valid: %q", attrs.BIC))
+	}
+
+	if preset, ok := CountryPresets[attrs.Country]; ok {
+		switch {
+		case preset.BankIDLength == 0 && attrs.BankID != "":
+			problems = append(problems, fmt.Sprintf("bank_id is not used in %s", attrs.Country))
+		case preset.BankIDLength > 0 && attrs.BankID != "" && !isDigits(attrs.BankID, preset.BankIDLength):
+			problems = append(problems, fmt.Sprintf("bank_id must have %d digits in %s: %q", preset.BankIDLength, attrs.Country, attrs.BankID))
+		}
+		if preset.BICRequired && attrs.BIC == "" {
+			problems = append(problems, fmt.Sprintf("bic is required in %s", attrs.Country))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func isDigits(v string, n int) bool {
+	if len(v) != n {
+		return false
+	}
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
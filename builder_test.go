@@ -0,0 +1,98 @@
+package form3
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccountBuilder(t *testing.T) {
+	builder := NewAccountBuilder().
+		OrganisationID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c").
+		Country(CountryGB).
+		BankID("400300").
+		BIC("NWBKGB22").
+		Name("Jane Doe")
+
+	account, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Regexp(t, uuidPattern, account.ID)
+	assert.Equal(t, "accounts", account.Type)
+	assert.Equal(t, &AccountAttributes{
+		Country:      CountryGB,
+		BaseCurrency: CurrencyGBP,
+		BankIDCode:   BankIDCodeGBDSC,
+		BankID:       "400300",
+		BIC:          "NWBKGB22",
+		Name:         "Jane Doe",
+	}, account.Attributes)
+
+	again := builder.Name("John Doe").MustBuild()
+	assert.Equal(t, account.ID, again.ID)
+	assert.Equal(t, "Jane Doe", account.Attributes.Name)
+}
+
+func TestAccountBuilder_Presets(t *testing.T) {
+	account := NewAccountBuilder().OrganisationID(CreateUUID()).Country(CountryFR).MustBuild()
+	assert.Equal(t, BankIDCodeFR, account.Attributes.BankIDCode)
+	assert.Equal(t, CurrencyEUR, account.Attributes.BaseCurrency)
+
+	account = NewAccountBuilder().OrganisationID(CreateUUID()).Country(CountryDE).BaseCurrency(CurrencyUSD).MustBuild()
+	assert.Equal(t, CurrencyUSD, account.Attributes.BaseCurrency)
+
+	account = NewAccountBuilder().OrganisationID(CreateUUID()).Country("FI").MustBuild()
+	assert.Equal(t, BankIDCode(""), account.Attributes.BankIDCode)
+
+	for country, preset := range CountryPresets {
+		assert.Equal(t, country, preset.BankIDCode.Country())
+	}
+}
+
+func TestAccountBuilder_Invalid(t *testing.T) {
+	_, err := NewAccountBuilder().ID("1").Country("XX").Build()
+	assert.EqualError(t, err, `id must be a UUID: "1"; organisation_id must be a UUID: ""; country is not an ISO 3166 code: "XX"`)
+
+	builder := NewAccountBuilder().OrganisationID(CreateUUID())
+
+	_, err = builder.Country(CountryDE).BankID("1234").Build()
+	assert.EqualError(t, err, `bank_id must have 8 digits in DE: "1234"`)
+
+	_, err = builder.Country(CountryGB).BankID("400300").Build()
+	assert.EqualError(t, err, "bic is required in GB")
+
+	_, err = builder.Country(CountryNL).BankID("400300").BIC("ABNANL2A").Build()
+	assert.EqualError(t, err, "bank_id is not used in NL")
+
+	_, err = builder.Country(CountryFR).BankID("").BankIDCode(BankIDCodeGBDSC).Build()
+	assert.EqualError(t, err, "bank_id_code GBDSC is not used in FR")
+
+	_, err = builder.Country(CountryGB).BaseCurrency("GBX").BIC("NWB").Build()
+	assert.EqualError(t, err, `base_currency is not an ISO 4217 code: "GBX"; bic is not valid: "NWB"`)
+
+	_, err = builder.Country(CountryGB).BaseCurrency(CurrencyGBP).BankIDCode("GBSDC").BIC("NWBKGB22").Build()
+	assert.EqualError(t, err, `bank_id_code is not supported: "GBSDC"`)
+
+	assert.EqualError(t, ValidateAccount(MakeAccount(CreateUUID(), CreateUUID())), "attributes are required")
+
+	assert.Panics(t, func() { NewAccountBuilder().MustBuild() })
+}
+
+func TestRandomAccount(t *testing.T) {
+	seen := make(map[string]bool)
+	for country := range CountryPresets {
+		account := RandomAccount(country)
+		assert.Nil(t, ValidateAccount(account))
+		assert.Equal(t, country, account.Attributes.Country)
+		assert.False(t, seen[account.ID])
+		seen[account.ID] = true
+	}
+
+	account := NewAccountBuilder().Random().MustBuild()
+	assert.Equal(t, CountryGB, account.Attributes.Country)
+	assert.Len(t, account.Attributes.BankID, 6)
+
+	account = NewAccountBuilder().Country(CountryDE).BankID("12345678").Name("Jane Doe").CustomerID("C-1").Random().MustBuild()
+	assert.Equal(t, "12345678", account.Attributes.BankID)
+	assert.Equal(t, "Jane Doe", account.Attributes.Name)
+	assert.Equal(t, "C-1", account.Attributes.CustomerID)
+	assert.NotEmpty(t, account.Attributes.AccountNumber)
+}
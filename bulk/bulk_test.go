@@ -0,0 +1,27 @@
+package bulk
+
+import (
+	"fmt"
+	"github.com/ig-hit/form3"
+	"net/http"
+	"net/http/httptest"
+)
+
+const (
+	orgID = "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+)
+
+func setup() (service *form3.AccountsService, mux *http.ServeMux, teardown func()) {
+	mux = http.NewServeMux()
+	server := httptest.NewServer(mux)
+	service = form3.CreateAccountsServiceWithOptions(&form3.ClientOptions{
+		Timeout:      3000,
+		BaseEndpoint: server.URL,
+	})
+
+	return service, mux, server.Close
+}
+
+func accountID(n int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+}
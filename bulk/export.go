@@ -0,0 +1,91 @@
+package bulk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"io"
+	"strconv"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+const exportPageSize = 100
+
+type Exporter struct {
+	Service form3.AccountsAPI
+
+	// Columns written in CSV format, DefaultMapping when nil
+	Mapping Mapping
+
+	// Size of the pages requested from List, 100 when not set
+	PageSize int
+}
+
+// Writes all accounts of the organisation, returns the number of exported accounts.
+func (ex *Exporter) Export(ctx context.Context, orgID string, format Format, w io.Writer) (int, error) {
+	mapping := ex.Mapping
+	if mapping == nil {
+		mapping = DefaultMapping
+	}
+	if err := mapping.Validate(); err != nil {
+		return 0, err
+	}
+
+	var write func(account *form3.Account) error
+	var flush func() error
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(mapping.headers()); err != nil {
+			return 0, err
+		}
+		write = func(account *form3.Account) error { return cw.Write(mapping.record(account)) }
+		flush = func() error { cw.Flush(); return cw.Error() }
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		write = func(account *form3.Account) error { return encoder.Encode(account) }
+		flush = func() error { return nil }
+	default:
+		return 0, fmt.Errorf("unknown export format %q", format)
+	}
+
+	pageSize := ex.PageSize
+	if pageSize <= 0 {
+		pageSize = exportPageSize
+	}
+
+	exported := 0
+	for page := 0; ; page++ {
+		options := &form3.ListOptions{
+			Number: strconv.Itoa(page),
+			Size:   pageSize,
+			Filter: map[string]string{"organisation_id": orgID},
+		}
+
+		accounts, _, err := ex.Service.List(ctx, options)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, account := range accounts {
+			if err := write(account); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if len(accounts) < pageSize {
+			break
+		}
+	}
+
+	return exported, flush()
+}
@@ -0,0 +1,78 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Registers list endpoint serving n accounts of the organisation.
+func handleList(t *testing.T, mux *http.ServeMux, n int) {
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, orgID, query.Get("filter[organisation_id]"))
+		number, _ := strconv.Atoi(query.Get("page[number]"))
+		size, _ := strconv.Atoi(query.Get("page[size]"))
+
+		page := []*form3.Account{}
+		for i := number*size + 1; i <= (number+1)*size && i <= n; i++ {
+			account := form3.MakeAccount(accountID(i), orgID)
+			account.Attributes = &form3.AccountAttributes{Country: "GB", Name: fmt.Sprintf("Name %d", i)}
+			page = append(page, account)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": page})
+	})
+}
+
+func TestExporter_ExportCSV(t *testing.T) {
+	service, mux, teardown := setup()
+	defer teardown()
+	handleList(t, mux, 5)
+
+	out := &bytes.Buffer{}
+	exporter := &Exporter{
+		Service:  service,
+		Mapping:  Mapping{{Header: "id", Field: "id"}, {Header: "name", Field: "attributes.name"}},
+		PageSize: 2,
+	}
+	exported, err := exporter.Export(context.Background(), orgID, FormatCSV, out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5, exported)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 6, len(lines))
+	assert.Equal(t, "id,name", lines[0])
+	assert.Equal(t, accountID(5)+",Name 5", lines[5])
+}
+
+func TestExporter_ExportNDJSON(t *testing.T) {
+	service, mux, teardown := setup()
+	defer teardown()
+	handleList(t, mux, 4)
+
+	out := &bytes.Buffer{}
+	exported, err := (&Exporter{Service: service, PageSize: 2}).Export(context.Background(), orgID, FormatNDJSON, out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, exported)
+
+	decoder := json.NewDecoder(out)
+	for i := 1; i <= 4; i++ {
+		account := new(form3.Account)
+		assert.Nil(t, decoder.Decode(account))
+		assert.Equal(t, accountID(i), account.ID)
+	}
+}
+
+func TestExporter_UnknownFormat(t *testing.T) {
+	_, err := (&Exporter{}).Export(context.Background(), orgID, "xml", &bytes.Buffer{})
+	assert.EqualError(t, err, `unknown export format "xml"`)
+}
@@ -0,0 +1,258 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Outcome of a row import.
+type Status string
+
+const (
+	StatusCreated Status = "created"
+	StatusInvalid Status = "invalid"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+var reportHeader = []string{"row", "id", "status", "error"}
+
+type Importer struct {
+	Service form3.AccountsAPI
+
+	// Columns of the CSV, DefaultMapping when nil
+	Mapping Mapping
+
+	// Number of accounts created in parallel, 1 when not set
+	Concurrency int
+
+	// File recording IDs of created accounts, rows with these IDs are skipped so an import
+	// can be resumed, also from an edited file
+	CheckpointPath string
+
+	// Per-row results written as CSV: row, id, status, error
+	Report io.Writer
+}
+
+// Rows by outcome.
+type Summary map[Status]int
+
+type rowResult struct {
+	row    int
+	id     string
+	status Status
+	err    error
+}
+
+type rowJob struct {
+	row     int
+	account *form3.Account
+}
+
+// Imports accounts from CSV with a header line, rows are numbered from 1 after the header.
+// Malformed rows are reported as invalid, only failing to read the input stops the import.
+func (im *Importer) Import(ctx context.Context, r io.Reader) (Summary, error) {
+	mapping := im.Mapping
+	if mapping == nil {
+		mapping = DefaultMapping
+	}
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	headerRecord, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	header := make(map[string]int, len(headerRecord))
+	for i, h := range headerRecord {
+		header[h] = i
+	}
+
+	checkpoint, err := openCheckpoint(im.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+	defer checkpoint.close()
+
+	report := newReport(im.Report)
+	summary := Summary{}
+	var mu sync.Mutex
+	collect := func(result rowResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		summary[result.status]++
+		report.write(result)
+		if result.status == StatusCreated {
+			checkpoint.add(result.id)
+		}
+	}
+
+	concurrency := im.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan rowJob)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := rowResult{row: job.row, id: job.account.ID, status: StatusCreated}
+				if _, _, err := im.Service.Create(ctx, job.account); err != nil {
+					result.status, result.err = StatusFailed, err
+				}
+				collect(result)
+			}
+		}()
+	}
+
+	var readErr error
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			// the record is returned along with ErrFieldCount, its ID may still be read
+			id := ""
+			if record != nil {
+				id = mapping.account(header, record).ID
+			}
+			collect(rowResult{row: row, id: id, status: StatusInvalid, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			readErr = fmt.Errorf("reading row %d: %v", row, err)
+			break
+		}
+
+		account := mapping.account(header, record)
+		if checkpoint.contains(account.ID) {
+			collect(rowResult{row: row, id: account.ID, status: StatusSkipped})
+			continue
+		}
+		if err := form3.ValidateAccount(account); err != nil {
+			collect(rowResult{row: row, id: account.ID, status: StatusInvalid, err: err})
+			continue
+		}
+
+		select {
+		case jobs <- rowJob{row: row, account: account}:
+		case <-ctx.Done():
+			readErr = ctx.Err()
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if err := report.flush(); err != nil && readErr == nil {
+		readErr = err
+	}
+	if err := checkpoint.err; err != nil && readErr == nil {
+		readErr = err
+	}
+
+	return summary, readErr
+}
+
+type report struct {
+	w *csv.Writer
+}
+
+func newReport(w io.Writer) *report {
+	if w == nil {
+		return &report{}
+	}
+
+	r := &report{w: csv.NewWriter(w)}
+	_ = r.w.Write(reportHeader)
+	return r
+}
+
+func (r *report) write(result rowResult) {
+	if r.w == nil {
+		return
+	}
+
+	message := ""
+	if result.err != nil {
+		message = result.err.Error()
+	}
+	_ = r.w.Write([]string{strconv.Itoa(result.row), result.id, string(result.status), message})
+}
+
+func (r *report) flush() error {
+	if r.w == nil {
+		return nil
+	}
+
+	r.w.Flush()
+	return r.w.Error()
+}
+
+// IDs of created accounts, one per line, appended as rows complete.
+type checkpoint struct {
+	ids  map[string]bool
+	file *os.File
+	err  error
+}
+
+func openCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{ids: make(map[string]bool)}
+	if path == "" {
+		return c, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// a partially written last line does not match an ID, the row is imported again
+		if id := scanner.Text(); id != "" {
+			c.ids[id] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	c.file = file
+	return c, nil
+}
+
+func (c *checkpoint) contains(id string) bool {
+	return id != "" && c.ids[id]
+}
+
+func (c *checkpoint) add(id string) {
+	if c.file == nil || c.err != nil {
+		return
+	}
+
+	_, c.err = fmt.Fprintf(c.file, "%s\n", id)
+}
+
+func (c *checkpoint) close() {
+	if c.file != nil {
+		_ = c.file.Close()
+	}
+}
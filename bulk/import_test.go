@@ -0,0 +1,141 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func csvInput(rows ...string) string {
+	return "id,organisation_id,country,bic,name\n" + strings.Join(rows, "\n") + "\n"
+}
+
+func row(n int, country string) string {
+	return fmt.Sprintf("%s,%s,%s,NWBKGB22,Name %d", accountID(n), orgID, country, n)
+}
+
+// Registers create endpoint recording created IDs, fail IDs get 500.
+func handleCreate(mux *http.ServeMux, fail map[string]bool) func() []string {
+	var mu sync.Mutex
+	var created []string
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		payload := struct{ Data *form3.Account }{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		if fail[payload.Data.ID] {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprint(w, `{"error_message":"temporary failure"}`)
+			return
+		}
+
+		mu.Lock()
+		created = append(created, payload.Data.ID)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(payload)
+	})
+
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([]string(nil), created...)
+		sort.Strings(sorted)
+		return sorted
+	}
+}
+
+func TestImporter_Import(t *testing.T) {
+	service, mux, teardown := setup()
+	defer teardown()
+	created := handleCreate(mux, map[string]bool{accountID(3): true})
+
+	report := &bytes.Buffer{}
+	importer := &Importer{Service: service, Concurrency: 4, Report: report}
+	summary, err := importer.Import(context.Background(), strings.NewReader(csvInput(
+		row(1, "GB"), row(2, "gb"), row(3, "DE"), row(4, "FR"),
+	)))
+
+	assert.Nil(t, err)
+	assert.Equal(t, Summary{StatusCreated: 2, StatusInvalid: 1, StatusFailed: 1}, summary)
+	assert.Equal(t, []string{accountID(1), accountID(4)}, created())
+
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	assert.Equal(t, "row,id,status,error", lines[0])
+	sort.Strings(lines[1:])
+	assert.Equal(t, []string{
+		fmt.Sprintf("1,%s,created,", accountID(1)),
+		fmt.Sprintf(`2,%s,invalid,"country is not an ISO 3166 code: ""gb"""`, accountID(2)),
+		fmt.Sprintf("3,%s,failed,temporary failure", accountID(3)),
+		fmt.Sprintf("4,%s,created,", accountID(4)),
+	}, lines[1:])
+}
+
+func TestImporter_ResumesFromCheckpoint(t *testing.T) {
+	service, mux, teardown := setup()
+	defer teardown()
+	fail := map[string]bool{accountID(2): true}
+	created := handleCreate(mux, fail)
+
+	dir, _ := ioutil.TempDir("", "bulk")
+	defer os.RemoveAll(dir)
+	checkpointPath := filepath.Join(dir, "import.checkpoint")
+
+	input := csvInput(row(1, "GB"), row(2, "GB"), row(3, "GB"))
+	importer := &Importer{Service: service, CheckpointPath: checkpointPath}
+
+	summary, err := importer.Import(context.Background(), strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, Summary{StatusCreated: 2, StatusFailed: 1}, summary)
+
+	// rows are matched by ID, so the file may be reordered or extended between runs
+	delete(fail, accountID(2))
+	input = csvInput(row(4, "GB"), row(3, "GB"), row(2, "GB"), row(1, "GB"))
+	summary, err = importer.Import(context.Background(), strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, Summary{StatusCreated: 2, StatusSkipped: 2}, summary)
+	assert.Equal(t, []string{accountID(1), accountID(2), accountID(3), accountID(4)}, created())
+}
+
+func TestImporter_MalformedRows(t *testing.T) {
+	service, mux, teardown := setup()
+	defer teardown()
+	created := handleCreate(mux, nil)
+
+	report := &bytes.Buffer{}
+	importer := &Importer{Service: service, Report: report}
+	summary, err := importer.Import(context.Background(), strings.NewReader(csvInput(
+		row(1, "GB"), accountID(2)+",extra", `x,a"b,GB,NWBKGB22,Name`, row(4, "GB"),
+	)))
+
+	assert.Nil(t, err)
+	assert.Equal(t, Summary{StatusCreated: 2, StatusInvalid: 2}, summary)
+	assert.Equal(t, []string{accountID(1), accountID(4)}, created())
+
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	sort.Strings(lines[1:])
+	assert.Equal(t, []string{
+		fmt.Sprintf("1,%s,created,", accountID(1)),
+		fmt.Sprintf("2,%s,invalid,wrong number of fields", accountID(2)),
+		`3,x,invalid,"bare "" in non-quoted-field"`,
+		fmt.Sprintf("4,%s,created,", accountID(4)),
+	}, lines[1:])
+}
+
+func TestImporter_InvalidMapping(t *testing.T) {
+	importer := &Importer{Mapping: Mapping{{Header: "x", Field: "y"}}}
+	_, err := importer.Import(context.Background(), strings.NewReader(csvInput()))
+	assert.EqualError(t, err, `column "x" maps to unknown field "y"`)
+}
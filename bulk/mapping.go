@@ -0,0 +1,178 @@
+// Package bulk imports accounts from CSV and exports them to CSV or NDJSON.
+package bulk
+
+import (
+	"fmt"
+	"github.com/ig-hit/form3"
+)
+
+// Maps a CSV column to an account field path such as "attributes.country".
+type Column struct {
+	Header string `json:"header"`
+	Field  string `json:"field"`
+}
+
+// Ordered list of columns, declared in code or loaded from JSON.
+type Mapping []Column
+
+// Columns named after the account field paths.
+var DefaultMapping = Mapping{
+	{Header: "id", Field: "id"},
+	{Header: "organisation_id", Field: "organisation_id"},
+	{Header: "country", Field: "attributes.country"},
+	{Header: "base_currency", Field: "attributes.base_currency"},
+	{Header: "bank_id", Field: "attributes.bank_id"},
+	{Header: "bank_id_code", Field: "attributes.bank_id_code"},
+	{Header: "account_number", Field: "attributes.account_number"},
+	{Header: "bic", Field: "attributes.bic"},
+	{Header: "iban", Field: "attributes.iban"},
+	{Header: "customer_id", Field: "attributes.customer_id"},
+	{Header: "name", Field: "attributes.name"},
+}
+
+type field struct {
+	get func(a *form3.Account) string
+	set func(a *form3.Account, v string)
+}
+
+func attributes(a *form3.Account) *form3.AccountAttributes {
+	if a.Attributes == nil {
+		a.Attributes = &form3.AccountAttributes{}
+	}
+	return a.Attributes
+}
+
+func privateIdentification(a *form3.Account) *form3.PrivateIdentification {
+	attrs := attributes(a)
+	if attrs.PrivateIdentification == nil {
+		attrs.PrivateIdentification = &form3.PrivateIdentification{}
+	}
+	return attrs.PrivateIdentification
+}
+
+// Attributes of the account for reading, empty ones are not attached to it.
+func readAttributes(a *form3.Account) *form3.AccountAttributes {
+	if a.Attributes == nil {
+		return &form3.AccountAttributes{}
+	}
+	return a.Attributes
+}
+
+func readPrivateIdentification(a *form3.Account) *form3.PrivateIdentification {
+	if a.Attributes == nil || a.Attributes.PrivateIdentification == nil {
+		return &form3.PrivateIdentification{}
+	}
+	return a.Attributes.PrivateIdentification
+}
+
+var fields = map[string]field{
+	"id": {
+		get: func(a *form3.Account) string { return a.ID },
+		set: func(a *form3.Account, v string) { a.ID = v },
+	},
+	"organisation_id": {
+		get: func(a *form3.Account) string { return a.OrganisationID },
+		set: func(a *form3.Account, v string) { a.OrganisationID = v },
+	},
+	"attributes.country": {
+		get: func(a *form3.Account) string { return string(readAttributes(a).Country) },
+		set: func(a *form3.Account, v string) { attributes(a).Country = form3.Country(v) },
+	},
+	"attributes.base_currency": {
+		get: func(a *form3.Account) string { return string(readAttributes(a).BaseCurrency) },
+		set: func(a *form3.Account, v string) { attributes(a).BaseCurrency = form3.Currency(v) },
+	},
+	"attributes.bank_id": {
+		get: func(a *form3.Account) string { return readAttributes(a).BankID },
+		set: func(a *form3.Account, v string) { attributes(a).BankID = v },
+	},
+	"attributes.bank_id_code": {
+		get: func(a *form3.Account) string { return string(readAttributes(a).BankIDCode) },
+		set: func(a *form3.Account, v string) { attributes(a).BankIDCode = form3.BankIDCode(v) },
+	},
+	"attributes.account_number": {
+		get: func(a *form3.Account) string { return readAttributes(a).AccountNumber },
+		set: func(a *form3.Account, v string) { attributes(a).AccountNumber = v },
+	},
+	"attributes.bic": {
+		get: func(a *form3.Account) string { return readAttributes(a).BIC },
+		set: func(a *form3.Account, v string) { attributes(a).BIC = v },
+	},
+	"attributes.iban": {
+		get: func(a *form3.Account) string { return readAttributes(a).IBAN },
+		set: func(a *form3.Account, v string) { attributes(a).IBAN = v },
+	},
+	"attributes.customer_id": {
+		get: func(a *form3.Account) string { return readAttributes(a).CustomerID },
+		set: func(a *form3.Account, v string) { attributes(a).CustomerID = v },
+	},
+	"attributes.name": {
+		get: func(a *form3.Account) string { return readAttributes(a).Name },
+		set: func(a *form3.Account, v string) { attributes(a).Name = v },
+	},
+	"attributes.private_identification.birth_date": {
+		get: func(a *form3.Account) string { return readPrivateIdentification(a).BirthDate },
+		set: func(a *form3.Account, v string) { privateIdentification(a).BirthDate = v },
+	},
+	"attributes.private_identification.birth_country": {
+		get: func(a *form3.Account) string { return string(readPrivateIdentification(a).BirthCountry) },
+		set: func(a *form3.Account, v string) { privateIdentification(a).BirthCountry = form3.Country(v) },
+	},
+	"attributes.private_identification.identification": {
+		get: func(a *form3.Account) string { return readPrivateIdentification(a).Identification },
+		set: func(a *form3.Account, v string) { privateIdentification(a).Identification = v },
+	},
+	"attributes.private_identification.city": {
+		get: func(a *form3.Account) string { return readPrivateIdentification(a).City },
+		set: func(a *form3.Account, v string) { privateIdentification(a).City = v },
+	},
+	"attributes.private_identification.country": {
+		get: func(a *form3.Account) string { return string(readPrivateIdentification(a).Country) },
+		set: func(a *form3.Account, v string) { privateIdentification(a).Country = form3.Country(v) },
+	},
+}
+
+// Checks that every column maps to a known field.
+func (m Mapping) Validate() error {
+	if len(m) == 0 {
+		return fmt.Errorf("mapping has no columns")
+	}
+
+	for _, c := range m {
+		if _, ok := fields[c.Field]; !ok {
+			return fmt.Errorf("column %q maps to unknown field %q", c.Header, c.Field)
+		}
+	}
+
+	return nil
+}
+
+func (m Mapping) headers() []string {
+	headers := make([]string, len(m))
+	for i, c := range m {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+// Builds account from the CSV record, header gives the position of every column.
+func (m Mapping) account(header map[string]int, record []string) *form3.Account {
+	account := form3.MakeAccount("", "")
+	for _, c := range m {
+		i, ok := header[c.Header]
+		if !ok || i >= len(record) || record[i] == "" {
+			continue
+		}
+		fields[c.Field].set(account, record[i])
+	}
+
+	return account
+}
+
+func (m Mapping) record(account *form3.Account) []string {
+	record := make([]string, len(m))
+	for i, c := range m {
+		record[i] = fields[c.Field].get(account)
+	}
+	return record
+}
@@ -0,0 +1,42 @@
+package bulk
+
+import (
+	"encoding/json"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMapping_Validate(t *testing.T) {
+	assert.Nil(t, DefaultMapping.Validate())
+	assert.EqualError(t, Mapping{}.Validate(), "mapping has no columns")
+	assert.EqualError(
+		t,
+		Mapping{{Header: "Land", Field: "attributes.land"}}.Validate(),
+		`column "Land" maps to unknown field "attributes.land"`,
+	)
+}
+
+func TestMapping_FromJSON(t *testing.T) {
+	var mapping Mapping
+	err := json.Unmarshal([]byte(`[
+		{"header": "Account ID", "field": "id"},
+		{"header": "Born", "field": "attributes.private_identification.birth_date"}
+	]`), &mapping)
+	assert.Nil(t, err)
+
+	account := mapping.account(map[string]int{"Born": 0, "Account ID": 1}, []string{"2017-07-23", "1"})
+	assert.Equal(t, "1", account.ID)
+	assert.Equal(t, "2017-07-23", account.Attributes.PrivateIdentification.BirthDate)
+	assert.Equal(t, []string{"1", "2017-07-23"}, mapping.record(account))
+}
+
+func TestMapping_RecordDoesNotModifyAccount(t *testing.T) {
+	mapping := append(Mapping{{Header: "birth_date", Field: "attributes.private_identification.birth_date"}}, DefaultMapping...)
+	account := form3.MakeAccount("1", "2")
+
+	record := mapping.record(account)
+	assert.Equal(t, "1", record[1])
+	assert.Equal(t, "", record[0])
+	assert.Nil(t, account.Attributes)
+}
@@ -0,0 +1,160 @@
+package form3
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Options of the AccountsService.ByID cache.
+type CacheOptions struct {
+	// How long a cached account is served without asking the API
+	TTL time.Duration
+
+	// Least recently used accounts are evicted above this number, 1000 when not set
+	MaxEntries int
+}
+
+type CacheStats struct {
+	// Accounts served from the cache without a request
+	Hits uint64
+
+	// Accounts fetched from the API
+	Misses uint64
+
+	// Stale accounts confirmed by the API to be unchanged (304 Not Modified)
+	Revalidations uint64
+}
+
+const defaultCacheMaxEntries = 1000
+
+type accountCacheEntry struct {
+	id           string
+	account      *Account
+	expires      time.Time
+	etag         string
+	lastModified string
+}
+
+// LRU cache of accounts by ID, accounts are copied in and out
+// so callers are free to modify what they get.
+type accountCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
+}
+
+func newAccountCache(options *CacheOptions) *accountCache {
+	if options == nil {
+		return nil
+	}
+
+	c := &accountCache{
+		ttl:        options.TTL,
+		maxEntries: options.MaxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = defaultCacheMaxEntries
+	}
+
+	return c
+}
+
+// Returns the account when it is fresh, otherwise the stale entry
+// when it can be revalidated, nil when it must be fetched again.
+func (c *accountCache) lookup(id string) (*Account, *accountCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return nil, nil
+	}
+
+	c.lru.MoveToFront(el)
+	entry := el.Value.(*accountCacheEntry)
+	if c.now().Before(entry.expires) {
+		c.stats.Hits++
+		return entry.account.Clone(), nil
+	}
+
+	if entry.etag == "" && entry.lastModified == "" {
+		return nil, nil
+	}
+
+	return nil, entry
+}
+
+// Adds conditional headers validating the stale entry.
+func (e *accountCacheEntry) setValidators(req *http.Request) {
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		req.Header.Set("If-Modified-Since", e.lastModified)
+	}
+}
+
+// Extends the stale entry confirmed to be unchanged.
+func (c *accountCache) revalidated(entry *accountCacheEntry) *Account {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Revalidations++
+	entry.expires = c.now().Add(c.ttl)
+
+	return entry.account.Clone()
+}
+
+func (c *accountCache) store(account *Account, header http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Misses++
+
+	entry := &accountCacheEntry{
+		id:           account.ID,
+		account:      account.Clone(),
+		expires:      c.now().Add(c.ttl),
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+	}
+
+	if el, ok := c.entries[account.ID]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[account.ID] = c.lru.PushFront(entry)
+	if c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*accountCacheEntry).id)
+	}
+}
+
+func (c *accountCache) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		c.lru.Remove(el)
+		delete(c.entries, id)
+	}
+}
+
+func (c *accountCache) snapshotStats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
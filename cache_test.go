@@ -0,0 +1,140 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func setupCachedAccounts(options *CacheOptions) (service *AccountsService, mux *http.ServeMux, clock *time.Time, teardown func()) {
+	service, mux, _, teardown = setupAccounts()
+	service.client.accountsCache = newAccountCache(options)
+
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	clock = &now
+	service.client.accountsCache.now = func() time.Time { return *clock }
+
+	return service, mux, clock, teardown
+}
+
+func TestAccountsService_ByIDCached(t *testing.T) {
+	service, mux, clock, teardown := setupCachedAccounts(&CacheOptions{TTL: time.Minute})
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"accounts","version":0}}`)
+	})
+
+	ctx := context.Background()
+	first, _, err := service.ByID(ctx, "1")
+	assert.Nil(t, err)
+
+	// callers can modify returned accounts without affecting the cache
+	first.OrganisationID = "modified"
+
+	second, resp, err := service.ByID(ctx, "1")
+	assert.Nil(t, err)
+	assert.True(t, resp.Cached)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, MakeAccount("1", "2"), second)
+	assert.Equal(t, 1, requests)
+
+	*clock = clock.Add(time.Minute)
+	_, resp, _ = service.ByID(ctx, "1")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.False(t, resp.Cached)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, service.CacheStats())
+}
+
+func TestAccountsService_ByIDRevalidates(t *testing.T) {
+	service, mux, clock, teardown := setupCachedAccounts(&CacheOptions{TTL: time.Minute})
+	defer teardown()
+
+	lastModified := "Fri, 01 May 2020 10:00:00 GMT"
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v0"` {
+			assert.Equal(t, lastModified, r.Header.Get("If-Modified-Since"))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v0"`)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"accounts","version":0}}`)
+	})
+
+	ctx := context.Background()
+	_, _, _ = service.ByID(ctx, "1")
+
+	*clock = clock.Add(2 * time.Minute)
+	account, resp, err := service.ByID(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, MakeAccount("1", "2"), account)
+
+	// revalidated account is fresh again
+	_, resp, _ = service.ByID(ctx, "1")
+	assert.True(t, resp.Cached)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Revalidations: 1}, service.CacheStats())
+}
+
+func TestAccountsService_DeleteInvalidatesCache(t *testing.T) {
+	service, mux, _, teardown := setupCachedAccounts(&CacheOptions{TTL: time.Minute})
+	defer teardown()
+
+	deleted := false
+	ctx := context.Background()
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			// a read completing while the delete is in flight caches the account again
+			_, _, _ = service.ByID(ctx, "1")
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if deleted {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"error_message":"record 1 does not exist"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"accounts","version":0}}`)
+	})
+
+	_, _, _ = service.ByID(ctx, "1")
+	_, _ = service.Delete(ctx, "1", 0)
+
+	account, _, err := service.ByID(ctx, "1")
+	assert.Nil(t, account)
+	assert.True(t, IsNotFound(err))
+	assert.True(t, IsNotFound(fmt.Errorf("fetching account: %w", err)), "wrapped errors are recognised")
+}
+
+func TestAccountCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newAccountCache(&CacheOptions{TTL: time.Minute, MaxEntries: 2})
+	cache.store(MakeAccount("1", "org"), http.Header{})
+	cache.store(MakeAccount("2", "org"), http.Header{})
+
+	account, _ := cache.lookup("1")
+	assert.NotNil(t, account)
+
+	cache.store(MakeAccount("3", "org"), http.Header{})
+	account, _ = cache.lookup("2")
+	assert.Nil(t, account)
+	account, _ = cache.lookup("1")
+	assert.NotNil(t, account)
+	account, _ = cache.lookup("3")
+	assert.NotNil(t, account)
+}
+
+func TestAccountsService_CacheStatsDisabled(t *testing.T) {
+	service, _, _, teardown := setupAccounts()
+	defer teardown()
+
+	assert.Equal(t, CacheStats{}, service.CacheStats())
+}
@@ -0,0 +1,319 @@
+// Package cassette records HTTP interactions into a JSONL file and replays them,
+// so tests can run against recorded API responses without the API itself.
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// Serves responses from the cassette, no request leaves the process.
+	ModeReplay Mode = iota
+
+	// Sends requests through the transport and writes every interaction to the cassette.
+	ModeRecord
+)
+
+// Value replacing recorded timestamps.
+const RedactedTimestamp = "1970-01-01T00:00:00Z"
+
+// Headers dropped from recorded interactions.
+var DefaultVolatileHeaders = []string{"Date", "Authorization", "X-Request-Id", "Set-Cookie", "Content-Length"}
+
+// JSON fields, at any depth, whose values are replaced by RedactedTimestamp.
+var DefaultTimestampFields = []string{"created_on", "modified_on"}
+
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches the request")
+
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type Options struct {
+	// Transport used in record mode, http.DefaultTransport when nil
+	Transport http.RoundTripper
+
+	// Headers not written to the cassette, DefaultVolatileHeaders when nil
+	VolatileHeaders []string
+
+	// Fields redacted in bodies, DefaultTimestampFields when nil
+	TimestampFields []string
+}
+
+// Recorder is an http.RoundTripper recording or replaying interactions.
+type Recorder struct {
+	mode            Mode
+	transport       http.RoundTripper
+	volatileHeaders []string
+	timestampFields map[string]bool
+
+	mu           sync.Mutex
+	file         *os.File
+	interactions []*Interaction
+	used         []bool
+}
+
+// Opens cassette at path. Record mode truncates the file, replay mode loads it.
+func New(path string, mode Mode, options *Options) (*Recorder, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	r := &Recorder{
+		mode:            mode,
+		transport:       options.Transport,
+		volatileHeaders: options.VolatileHeaders,
+		timestampFields: make(map[string]bool),
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+	if r.volatileHeaders == nil {
+		r.volatileHeaders = DefaultVolatileHeaders
+	}
+
+	timestampFields := options.TimestampFields
+	if timestampFields == nil {
+		timestampFields = DefaultTimestampFields
+	}
+	for _, f := range timestampFields {
+		r.timestampFields[f] = true
+	}
+
+	var err error
+	if mode == ModeRecord {
+		r.file, err = os.Create(path)
+	} else {
+		err = r.load(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Recorder) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		interaction := &Interaction{}
+		if err := json.Unmarshal(scanner.Bytes(), interaction); err != nil {
+			return fmt.Errorf("cassette: line %d: %v", line, err)
+		}
+		r.interactions = append(r.interactions, interaction)
+	}
+	r.used = make([]bool, len(r.interactions))
+
+	return scanner.Err()
+}
+
+// Closes the cassette file in record mode.
+func (r *Recorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	return r.file.Close()
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded *Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	interaction := &Interaction{
+		Request: recorded,
+		Response: &Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       r.redactBody(body),
+		},
+	}
+
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Serves the first unused matching interaction, or the last matching one
+// when all of them were used, so repeated identical requests keep working.
+func (r *Recorder) replay(req *http.Request, recorded *Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.interactions {
+		if !matches(interaction.Request, recorded) {
+			continue
+		}
+
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+	}
+	r.used[match] = true
+
+	response := r.interactions[match].Response
+	header := http.Header{}
+	for k, v := range response.Header {
+		header[k] = v
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+		StatusCode:    response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(response.Body)),
+		ContentLength: int64(len(response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) recordRequest(req *http.Request) (*Request, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return &Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Header: r.redactHeader(req.Header),
+		Body:   r.redactBody(body),
+	}, nil
+}
+
+func matches(recorded, req *Request) bool {
+	return recorded.Method == req.Method &&
+		recorded.Path == req.Path &&
+		recorded.Query == req.Query &&
+		recorded.Body == req.Body
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	redacted := http.Header{}
+	for k, v := range header {
+		redacted[k] = v
+	}
+	for _, h := range r.volatileHeaders {
+		redacted.Del(h)
+	}
+
+	if len(redacted) == 0 {
+		return nil
+	}
+
+	return redacted
+}
+
+// Normalizes JSON bodies: keys sorted, no whitespace and timestamps redacted.
+func (r *Recorder) redactBody(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(bytes.TrimSpace(body))
+	}
+
+	normalized, _ := json.Marshal(r.redactValue(v))
+	return string(normalized)
+}
+
+func (r *Recorder) redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if _, isString := item.(string); isString && r.timestampFields[k] {
+				value[k] = RedactedTimestamp
+				continue
+			}
+			value[k] = r.redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = r.redactValue(item)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,130 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	accountID = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+	orgID     = "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+)
+
+func createAccountsService(baseEndpoint string, recorder *Recorder) *form3.AccountsService {
+	return form3.CreateAccountsServiceWithOptions(&form3.ClientOptions{
+		Timeout:      3000,
+		BaseEndpoint: baseEndpoint,
+		Transport:    recorder,
+	})
+}
+
+func createAccount() *form3.Account {
+	return form3.NewAccountBuilder().
+		ID(accountID).
+		OrganisationID(orgID).
+		Country(form3.CountryDE).
+		BankID("12345678").
+		CustomerID("XXX-3").
+		Name("Jose Sanchez").
+		MustBuild()
+}
+
+// Runs the account lifecycle against the cassette recorded from the fake account API.
+func TestReplay_AccountLifecycle(t *testing.T) {
+	recorder, err := New("testdata/accounts.jsonl", ModeReplay, nil)
+	assert.Nil(t, err)
+	service := createAccountsService("http://localhost:8080/v1", recorder)
+	ctx := context.Background()
+
+	saved, resp, err := service.Create(ctx, createAccount())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.NotNil(t, saved.CreatedOn)
+	assert.Equal(t, form3.BankIDCodeDEBLZ, saved.Attributes.BankIDCode)
+
+	fetched, _, err := service.ByID(ctx, accountID)
+	assert.Nil(t, err)
+	assert.Equal(t, saved, fetched)
+
+	resp, err = service.Delete(ctx, accountID, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, resp, err = service.ByID(ctx, accountID)
+	assert.True(t, form3.IsNotFound(err))
+	assert.Contains(t, err.Error(), fmt.Sprintf("record %s does not exist", accountID))
+}
+
+func TestReplay_NoMatch(t *testing.T) {
+	recorder, err := New("testdata/accounts.jsonl", ModeReplay, nil)
+	assert.Nil(t, err)
+	service := createAccountsService("http://localhost:8080/v1", recorder)
+
+	_, _, err = service.ByID(context.Background(), "unknown")
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+func TestReplay_RepeatsLastMatch(t *testing.T) {
+	recorder, err := New("testdata/accounts.jsonl", ModeReplay, nil)
+	assert.Nil(t, err)
+	service := createAccountsService("http://localhost:8080/v1", recorder)
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound, http.StatusNotFound} {
+		_, resp, _ := service.ByID(context.Background(), accountID)
+		assert.Equal(t, status, resp.StatusCode)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "cassette")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "accounts.jsonl")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "volatile")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"data":{"id":"%s","organisation_id":"%s","type":"accounts",`+
+			`"created_on":"2020-05-01T10:00:00.000Z","version":0}}`, accountID, orgID)
+	}))
+
+	recorder, err := New(path, ModeRecord, nil)
+	assert.Nil(t, err)
+	recorded, _, err := createAccountsService(server.URL, recorder).Create(context.Background(), createAccount())
+	assert.Nil(t, err)
+	assert.Nil(t, recorder.Close())
+	server.Close()
+
+	cassette, _ := ioutil.ReadFile(path)
+	assert.Equal(t, 1, strings.Count(string(cassette), "\n"))
+	assert.NotContains(t, string(cassette), "volatile")
+	assert.NotContains(t, string(cassette), "2020-05-01")
+
+	recorder, err = New(path, ModeReplay, nil)
+	assert.Nil(t, err)
+	replayed, resp, err := createAccountsService(server.URL, recorder).Create(context.Background(), createAccount())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, recorded.ID, replayed.ID)
+	assert.Equal(t, RedactedTimestamp, replayed.CreatedOn.Format("2006-01-02T15:04:05Z"))
+}
+
+func TestRedactBody_NormalizesJSON(t *testing.T) {
+	recorder := &Recorder{timestampFields: map[string]bool{"modified_on": true}}
+
+	assert.Equal(
+		t,
+		`{"a":[{"modified_on":"1970-01-01T00:00:00Z"}],"b":1}`,
+		recorder.redactBody([]byte(`{ "b": 1, "a": [{"modified_on": "2020-01-01T00:00:00Z"}] }`)),
+	)
+	assert.Equal(t, "plain text", recorder.redactBody([]byte(" plain text\n")))
+}
@@ -10,25 +10,101 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
 
 type ClientOptions struct {
-	// milliseconds
+	// Deprecated: use DefaultTimeout. Milliseconds, used as DefaultTimeout when that is 0.
 	Timeout int
 
+	// Timeout of operations with none in OperationTimeouts or DefaultOperationTimeouts,
+	// the package DefaultTimeout when 0
+	DefaultTimeout time.Duration
+
+	// Timeouts by operation, e.g. accounts.List, or by method, e.g. List
+	OperationTimeouts map[string]time.Duration
+
 	// http://localhost:8080/v1
 	BaseEndpoint string
+
+	// Transport used to send requests, one tuned by TransportOptions when nil
+	Transport http.RoundTripper
+
+	// Connection pool, proxy and TLS settings of the transport built when Transport is nil
+	TransportOptions *TransportOptions
+
+	// Enables caching of AccountsService.ByID when set
+	AccountsCache *CacheOptions
+
+	// Limits all requests of the client
+	RateLimit *RateLimit
+
+	// Limits requests per endpoint, e.g. "POST /organisation/accounts", on top of RateLimit
+	EndpointRateLimits map[string]*RateLimit
+
+	// Fails requests fast with ErrCircuitOpen while the API keeps failing
+	CircuitBreaker *CircuitBreakerOptions
+
+	// Records spans and metrics of requests when set
+	Telemetry *TelemetryOptions
+
+	// Logs requests when set
+	Logging *LoggingOptions
+
+	// User-Agent header sent with every request
+	UserAgent string
+
+	// Retries failed idempotent requests when set
+	Retry *RetryPolicy
+
+	// Organisation stamped on created resources that have none
+	DefaultOrganisationID string
+
+	// Called before and after every request when set
+	Hooks *Hooks
+
+	// Authenticates requests when set
+	Auth *Auth
+
+	// Refuses destructive operations unless AllowDestructive is set
+	Production       bool
+	AllowDestructive bool
+
+	// Fails requests and responses with *InvalidCodeError on unknown bank ID codes,
+	// countries, currencies and account classifications, see ValidateCodes
+	StrictEnums bool
 }
 
 // A Client manages communication with the API.
 type Client struct {
 	httpClient *http.Client
 
+	accountsCache *accountCache
+
+	timeout           time.Duration
+	operationTimeouts map[string]time.Duration
+
+	hooks *Hooks
+
+	userAgent             string
+	defaultOrganisationID string
+	scopeOrganisationID   string
+
+	production       bool
+	allowDestructive bool
+
+	strictEnums bool
+
 	BaseURL *url.URL
 }
 
+// Organisation stamped on created resources that have none.
+func (c *Client) DefaultOrganisationID() string {
+	return c.defaultOrganisationID
+}
+
 type service struct {
 	client *Client
 }
@@ -38,8 +114,93 @@ type body struct {
 	Data interface{} `json:"data"`
 }
 
-type bodyError struct {
-	ErrorMessage string `json:"error_message"`
+// Specify pagination options.
+type ListOptions struct {
+	// Page number being requested: int or first|last
+	Number string
+
+	// Size of the page being requested
+	Size int
+
+	// Filter by attribute, e.g. {"bank_id_code": "GBDSC"} for filter[bank_id_code]=GBDSC
+	Filter map[string]string
+}
+
+// Appends pagination and filter query to the endpoint.
+func addListOptions(endpoint string, options *ListOptions) string {
+	if options == nil {
+		return endpoint
+	}
+
+	var query []string
+	if options.Number != "" || options.Size != 0 {
+		query = append(query, fmt.Sprintf("page[number]=%s&page[size]=%d", options.Number, options.Size))
+	}
+
+	keys := make([]string, 0, len(options.Filter))
+	for k := range options.Filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		query = append(query, fmt.Sprintf("filter[%s]=%s", k, url.QueryEscape(options.Filter[k])))
+	}
+
+	if len(query) == 0 {
+		return endpoint
+	}
+
+	return fmt.Sprintf("%s?%s", endpoint, strings.Join(query, "&"))
+}
+
+// A reference to another resource.
+type ResourceRef struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Relationship to other resources, as in {"data": [{"id": "..", "type": ".."}]}.
+type Relationship struct {
+	Data []ResourceRef `json:"data"`
+}
+
+// ID of the first related resource, empty if there is none.
+func (r *Relationship) ID() string {
+	if r == nil || len(r.Data) == 0 {
+		return ""
+	}
+
+	return r.Data[0].ID
+}
+
+func makeRelationship(id, resourceType string) *Relationship {
+	return &Relationship{Data: []ResourceRef{{ID: id, Type: resourceType}}}
+}
+
+type operationKey struct{}
+
+// Names the API operation the requests of ctx are made for, with the options of the call.
+func withOperation(ctx context.Context, name string, opts ...CallOption) context.Context {
+	if ctx == nil {
+		return nil
+	}
+
+	return withCallOptions(context.WithValue(ctx, operationKey{}, name), opts)
+}
+
+// Name of the API operation the request is made for, e.g. accounts.Create.
+func OperationFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// Rejects unknown codes in v when the client has StrictEnums set.
+func (c *Client) checkCodes(v interface{}) error {
+	if !c.strictEnums || v == nil {
+		return nil
+	}
+
+	return ValidateCodes(v)
 }
 
 func (c *Client) GET(url string, body interface{}) (*http.Request, error) {
@@ -50,6 +211,10 @@ func (c *Client) POST(url string, body interface{}) (*http.Request, error) {
 	return c.createRequest("POST", url, body)
 }
 
+func (c *Client) PATCH(url string, body interface{}) (*http.Request, error) {
+	return c.createRequest("PATCH", url, body)
+}
+
 func (c *Client) DELETE(url string, body interface{}) (*http.Request, error) {
 	return c.createRequest("DELETE", url, body)
 }
@@ -59,8 +224,14 @@ func (c *Client) createRequest(method, url string, payload interface{}) (*http.R
 
 	var data io.Reader
 	if payload != nil {
-		body := body{Data: payload}
-		dataJson, _ := json.Marshal(body)
+		if err := c.checkCodes(payload); err != nil {
+			return nil, err
+		}
+
+		dataJson, err := json.Marshal(body{Data: payload})
+		if err != nil {
+			return nil, err
+		}
 		data = bytes.NewBuffer(dataJson)
 	}
 
@@ -70,6 +241,9 @@ func (c *Client) createRequest(method, url string, payload interface{}) (*http.R
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 	req.Header.Add("Date", time.Now().Format(time.RFC850))
 
 	return req, nil
@@ -80,6 +254,35 @@ func (c *Client) Do(ctx context.Context, req *http.Request, target interface{})
 		return nil, errors.New("nil context is not allowed")
 	}
 
+	if c.hooks == nil {
+		return c.do(ctx, req, target)
+	}
+
+	info := c.hooks.requestInfo(ctx, req)
+	if c.hooks.BeforeRequest != nil {
+		if err := c.hooks.BeforeRequest(ctx, info); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	response, err := c.do(ctx, req, target)
+
+	if c.hooks.AfterResponse != nil {
+		result := &ResponseInfo{RequestInfo: *info, Duration: time.Since(start), Err: err}
+		if response != nil {
+			result.StatusCode = response.StatusCode
+		}
+		c.hooks.AfterResponse(ctx, result)
+	}
+
+	return response, err
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, target interface{}) (*Response, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	req = req.WithContext(ctx)
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -90,39 +293,51 @@ func (c *Client) Do(ctx context.Context, req *http.Request, target interface{})
 
 	response := newResponse(resp)
 
-	respText, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return response, err
+	// conditional request confirmed the cached copy, there is no body
+	if resp.StatusCode == http.StatusNotModified {
+		return response, nil
 	}
 
-	// check if there're any errors
-	be := &bodyError{}
-	_ = json.Unmarshal(respText, be)
-	if be.ErrorMessage != "" {
-		return response, errors.New(be.ErrorMessage)
-	}
-
-	// unpack into target
-	if target != nil {
-		body := &body{}
-		err = json.Unmarshal(respText, body)
-		if err != nil {
-			return response, err
-		}
+	err = decodeResponse(resp, target)
 
-		encoded, err := json.Marshal(body.Data)
-		if err == nil {
-			err = json.Unmarshal(encoded, target)
-		}
+	// streamed targets check every element as it is decoded
+	if _, streamed := target.(dataDecoder); err == nil && !streamed {
+		err = c.checkCodes(target)
 	}
 
+	// drain what the decoder left, so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
 	return response, err
 }
 
+// Returned by methods given nil data, e.g. Create and Update.
+var ErrNilData = errors.New("nil data is not allowed")
+
 type Response struct {
 	*http.Response
+
+	// Set when the result was served from the AccountsCache without a request,
+	// the response is then a synthetic 200 OK with no body
+	Cached bool
 }
 
 func newResponse(r *http.Response) *Response {
 	return &Response{Response: r}
 }
+
+func newCachedResponse(req *http.Request) *Response {
+	return &Response{
+		Response: &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		},
+		Cached: true,
+	}
+}
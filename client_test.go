@@ -1,9 +1,12 @@
 package form3
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -139,3 +142,140 @@ func TestClient_Do(t *testing.T) {
 		t.Errorf("Response body = %v, want %v", body, want)
 	}
 }
+
+func TestClient_DoError(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, `{"error_message":"bad"}`)
+	})
+	mux.HandleFunc("/y", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req, _ := client.createRequest("GET", "/x", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	assert.Equal(t, &ErrorResponse{Response: resp.Response, ErrorMessage: "bad"}, err)
+
+	req, _ = client.createRequest("GET", "/y", nil)
+	_, err = client.Do(context.Background(), req, nil)
+	assert.EqualError(t, err, "404 Not Found")
+}
+
+func TestClient_DoDecode(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	bodies := map[string]string{
+		"/data-first": `{"data":{"id":"1","attributes":{"name":"a"}},"error_message":"late"}`,
+		"/links":      `{"links":{"self":"/x","next":null},"meta":[1,{"a":[]}],"data":{"id":"1"}}`,
+		"/empty":      ``,
+		"/array":      `[1]`,
+		"/truncated":  `{"data":{"id":`,
+	}
+	for path, body := range bodies {
+		body := body
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, body)
+		})
+	}
+	do := func(path string, target interface{}) error {
+		req, _ := client.createRequest("GET", path, nil)
+		_, err := client.Do(context.Background(), req, target)
+		return err
+	}
+
+	account := new(Account)
+	assert.EqualError(t, do("/data-first", account), "late")
+
+	account = new(Account)
+	assert.Nil(t, do("/links", account))
+	assert.Equal(t, "1", account.ID)
+
+	assert.Nil(t, do("/empty", new(Account)))
+	assert.EqualError(t, do("/array", new(Account)), "response body is not a JSON object")
+	assert.NotNil(t, do("/truncated", new(Account)))
+	assert.Nil(t, do("/truncated", nil))
+}
+
+// Page of accounts as served by the API.
+func accountsPage(n int) []byte {
+	account := RandomAccount(CountryGB)
+	accounts := make([]*Account, n)
+	for i := range accounts {
+		accounts[i] = account
+	}
+	page, _ := json.Marshal(map[string]interface{}{"data": accounts, "links": map[string]string{"self": "/x"}})
+
+	return page
+}
+
+// Decoding as done before streaming: read the body, unmarshal for errors and
+// the envelope, then re-marshal data into the target.
+func decodeBuffered(r io.Reader, target interface{}) error {
+	text, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_ = json.Unmarshal(text, &ErrorResponse{})
+
+	body := &body{}
+	if err := json.Unmarshal(text, body); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(body.Data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, target)
+}
+
+func benchmarkDecode(b *testing.B, decode func(r *http.Response) error) {
+	page := accountsPage(500)
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		resp := &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(page))}
+		if err := decode(resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode_Buffered(b *testing.B) {
+	benchmarkDecode(b, func(r *http.Response) error {
+		return decodeBuffered(r.Body, new([]*Account))
+	})
+}
+
+func BenchmarkDecode_Streaming(b *testing.B) {
+	benchmarkDecode(b, func(r *http.Response) error {
+		return decodeResponse(r, new([]*Account))
+	})
+}
+
+func BenchmarkDecode_Each(b *testing.B) {
+	benchmarkDecode(b, func(r *http.Response) error {
+		return decodeResponse(r, dataDecoder(func(dec *json.Decoder) error {
+			return decodeArray(dec, func(dec *json.Decoder) error {
+				return dec.Decode(new(Account))
+			})
+		}))
+	})
+}
+
+func TestAddListOptions(t *testing.T) {
+	assert.Equal(t, "/foo", addListOptions("/foo", nil))
+	assert.Equal(t, "/foo", addListOptions("/foo", &ListOptions{}))
+	assert.Equal(t, "/foo?page[number]=first&page[size]=10", addListOptions("/foo", &ListOptions{Number: "first", Size: 10}))
+	assert.Equal(
+		t,
+		"/foo?page[number]=1&page[size]=2&filter[bank_id]=1+2&filter[country]=GB",
+		addListOptions("/foo", &ListOptions{Number: "1", Size: 2, Filter: map[string]string{"country": "GB", "bank_id": "1 2"}}),
+	)
+}
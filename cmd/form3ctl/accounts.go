@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const purgePageSize = 100
+
+type accountsCommand struct {
+	service form3.AccountsAPI
+	out     printer
+}
+
+func (c *accountsCommand) run(name string, args []string) error {
+	switch name {
+	case "create":
+		return c.create(args)
+	case "get":
+		return c.get(args)
+	case "list":
+		return c.list(args)
+	case "delete":
+		return c.delete(args)
+	case "purge":
+		return c.purge(args)
+	}
+
+	return errUsage
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+// Collects repeated -filter key=value flags.
+type filterFlag map[string]string
+
+func (f filterFlag) String() string {
+	return fmt.Sprint(map[string]string(f))
+}
+
+func (f filterFlag) Set(v string) error {
+	parts := strings.SplitN(v, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("filter must be key=value, got %q", v)
+	}
+	f[parts[0]] = parts[1]
+	return nil
+}
+
+func (c *accountsCommand) create(args []string) error {
+	fs := newFlagSet("create")
+	file := fs.String("file", "", "JSON file with the account")
+	id := fs.String("id", "", "account ID, generated when empty")
+	orgID := fs.String("organisation-id", "", "organisation ID")
+	attrs := &form3.AccountAttributes{}
+	fs.Var(&attrs.Country, "country", "country, ISO 3166 code")
+	fs.Var(&attrs.BaseCurrency, "base-currency", "base currency, ISO 4217 code")
+	fs.StringVar(&attrs.BankID, "bank-id", "", "bank ID")
+	fs.Var(&attrs.BankIDCode, "bank-id-code", "bank ID code, e.g. GBDSC")
+	fs.StringVar(&attrs.AccountNumber, "account-number", "", "account number")
+	fs.StringVar(&attrs.BIC, "bic", "", "BIC")
+	fs.StringVar(&attrs.IBAN, "iban", "", "IBAN")
+	fs.StringVar(&attrs.CustomerID, "customer-id", "", "customer ID")
+	fs.StringVar(&attrs.Name, "name", "", "account holder name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var account *form3.Account
+	if *file != "" {
+		data, err := ioutil.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+
+		account = new(form3.Account)
+		if err := json.Unmarshal(data, account); err != nil {
+			return fmt.Errorf("%s: %v", *file, err)
+		}
+		if account.Type == "" {
+			account.Type = "accounts"
+		}
+	} else {
+		if *orgID == "" {
+			return errors.New("create: -organisation-id is required")
+		}
+		if *id == "" {
+			*id = form3.CreateUUID()
+		}
+
+		account = form3.MakeAccount(*id, *orgID)
+		account.Attributes = attrs
+	}
+
+	saved, _, err := c.service.Create(context.Background(), account)
+	if err != nil {
+		return err
+	}
+
+	return c.out.PrintOne(saved)
+}
+
+func (c *accountsCommand) get(args []string) error {
+	fs := newFlagSet("get")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("get: account ID is required")
+	}
+
+	account, _, err := c.service.ByID(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return c.out.PrintOne(account)
+}
+
+func (c *accountsCommand) list(args []string) error {
+	fs := newFlagSet("list")
+	number := fs.String("page", "", "page number: int or first|last")
+	size := fs.Int("size", 0, "page size")
+	all := fs.Bool("all", false, "fetch all pages")
+	filter := filterFlag{}
+	fs.Var(filter, "filter", "filter as key=value, repeatable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if !*all {
+		accounts, _, err := c.service.List(ctx, &form3.ListOptions{Number: *number, Size: *size, Filter: filter})
+		if err != nil {
+			return err
+		}
+
+		return c.out.Print(accounts)
+	}
+
+	pageSize := *size
+	if pageSize == 0 {
+		pageSize = purgePageSize
+	}
+
+	var accounts []*form3.Account
+	for page := 0; ; page++ {
+		options := &form3.ListOptions{Number: strconv.Itoa(page), Size: pageSize, Filter: filter}
+		list, _, err := c.service.List(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		accounts = append(accounts, list...)
+		if len(list) < pageSize {
+			break
+		}
+	}
+
+	return c.out.Print(accounts)
+}
+
+func (c *accountsCommand) delete(args []string) error {
+	fs := newFlagSet("delete")
+	version := fs.Int("version", -1, "account version, fetched when not given")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("delete: account ID is required")
+	}
+
+	ctx := context.Background()
+	id := fs.Arg(0)
+	if *version < 0 {
+		account, _, err := c.service.ByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		*version = account.Version
+	}
+
+	_, err := c.service.Delete(ctx, id, *version)
+	return err
+}
+
+// Deletes accounts page by page, always taking the first page as deletion shifts the rest.
+func (c *accountsCommand) purge(args []string) error {
+	fs := newFlagSet("purge")
+	yes := fs.Bool("yes", false, "confirm deletion")
+	filter := filterFlag{}
+	fs.Var(filter, "filter", "filter as key=value, repeatable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes {
+		return errors.New("purge: refusing to delete accounts without -yes")
+	}
+
+	ctx := context.Background()
+	deleted := make(map[string]bool)
+	var purged []*form3.Account
+	for {
+		options := &form3.ListOptions{Number: "0", Size: purgePageSize, Filter: filter}
+		accounts, _, err := c.service.List(ctx, options)
+		if err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, account := range accounts {
+			if deleted[account.ID] {
+				return fmt.Errorf("purge: account %s is still listed after deletion", account.ID)
+			}
+
+			if _, err := c.service.Delete(ctx, account.ID, account.Version); err != nil {
+				return err
+			}
+			deleted[account.ID] = true
+			purged = append(purged, account)
+		}
+	}
+
+	return c.out.Print(purged)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/ig-hit/form3"
+)
+
+// Settings of the client, applied in order: the config file, its selected profile,
+// then environment variables and flags.
+type config struct {
+	file      *form3.Config
+	profile   *form3.Profile
+	overrides *form3.Config
+
+	allowDestructive bool
+}
+
+// Loads the config file using the keys of form3.Config, selects its profile and reads FORM3_* variables.
+func loadConfig(path, profileName string, getenv func(string) string) (*config, error) {
+	c := &config{file: &form3.Config{}}
+
+	if path != "" {
+		file, err := form3.LoadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		c.file = file
+	}
+
+	if profileName != "" || len(c.file.Profiles.Profiles) > 0 {
+		profile, err := c.file.Select(profileName)
+		if err != nil {
+			return nil, err
+		}
+		c.profile = profile
+	}
+
+	env, err := form3.ConfigFromGetenv(getenv)
+	if err != nil {
+		return nil, err
+	}
+	c.overrides = env
+
+	return c, nil
+}
+
+// Settings given by flags take precedence over all others.
+func (c *config) override(flags *form3.Config) {
+	c.overrides = c.overrides.Merge(flags)
+}
+
+func (c *config) client() (*form3.Client, error) {
+	options := []form3.Option{form3.WithConfig(c.file)}
+	if c.profile != nil {
+		options = append(options, form3.WithProfile(c.profile))
+	}
+	options = append(options, form3.WithConfig(c.overrides))
+	if c.allowDestructive {
+		options = append(options, form3.WithAllowDestructive(true))
+	}
+
+	return form3.NewClient(options...)
+}
+
+func (c *config) accountsService() (*form3.AccountsService, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	return form3.CreateAccountsService(client), nil
+}
@@ -0,0 +1,87 @@
+// Command form3ctl manages accounts from the command line.
+//
+//	form3ctl [global flags] accounts create|get|list|delete|purge [flags] [args]
+//
+// Global flags:
+//
+//	-config            path to a JSON or YAML config file, FORM3_CONFIG
+//	-profile           profile of the config file, FORM3_PROFILE
+//	-base-url          base endpoint of the API, FORM3_BASE_URL
+//	-timeout           timeout of operations without a default of their own, e.g. 3s, FORM3_TIMEOUT
+//	-allow-destructive allow deletes against a production profile
+//	-o                 output format: json, yaml, table or csv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+const usage = `usage: form3ctl [-config file] [-profile name] [-base-url url] [-timeout duration] [-allow-destructive] [-o json|yaml|table|csv] accounts <command> [flags] [args]
+
+commands:
+  create  create account from flags or a JSON file
+  get     get account by ID
+  list    list accounts, one page or all of them
+  delete  delete account by ID, the version is fetched unless given
+  purge   delete all accounts matching filters
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Getenv, os.Stdout); err != nil {
+		if err == errUsage || err == flag.ErrHelp {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+
+		fmt.Fprintln(os.Stderr, "form3ctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, getenv func(string) string, stdout io.Writer) error {
+	global := flag.NewFlagSet("form3ctl", flag.ContinueOnError)
+	global.SetOutput(ioutil.Discard)
+	configPath := global.String("config", getenv("FORM3_CONFIG"), "config file")
+	profileName := global.String("profile", getenv("FORM3_PROFILE"), "profile of the config file")
+	baseURL := global.String("base-url", "", "base endpoint of the API")
+	timeout := global.Duration("timeout", 0, "timeout of operations without a default")
+	allowDestructive := global.Bool("allow-destructive", false, "allow deletes against a production profile")
+	format := global.String("o", "json", "output format")
+
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := loadConfig(*configPath, *profileName, getenv)
+	if err != nil {
+		return err
+	}
+	config.override(&form3.Config{BaseURL: *baseURL, Timeout: *timeout})
+	config.allowDestructive = *allowDestructive
+
+	out, err := newPrinter(*format, stdout)
+	if err != nil {
+		return err
+	}
+
+	rest := global.Args()
+	if len(rest) < 2 || rest[0] != "accounts" {
+		return errUsage
+	}
+
+	service, err := config.accountsService()
+	if err != nil {
+		return err
+	}
+
+	cmd := &accountsCommand{service: service, out: out}
+	return cmd.run(rest[1], rest[2:])
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setup() (mux *http.ServeMux, form3ctl func(args ...string) (string, error), teardown func()) {
+	mux = http.NewServeMux()
+	server := httptest.NewServer(http.StripPrefix("/v1", mux))
+
+	env := map[string]string{"FORM3_BASE_URL": server.URL + "/v1"}
+	form3ctl = func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		err := run(args, func(k string) string { return env[k] }, out)
+		return out.String(), err
+	}
+
+	return mux, form3ctl, server.Close
+}
+
+const account = `{"id":"1","organisation_id":"2","type":"accounts","version":3,` +
+	`"attributes":{"country":"GB","base_currency":"GBP","bank_id":"400300","bank_id_code":"GBDSC","name":"Jane"}}`
+
+func TestRun_Get(t *testing.T) {
+	mux, form3ctl, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"data":%s}`, account)
+	})
+
+	out, err := form3ctl("accounts", "get", "1")
+	assert.Nil(t, err)
+
+	printed := new(form3.Account)
+	assert.Nil(t, json.Unmarshal([]byte(out), printed))
+	assert.Equal(t, form3.BankIDCodeGBDSC, printed.Attributes.BankIDCode)
+}
+
+func TestRun_CreateFromFlags(t *testing.T) {
+	mux, form3ctl, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"country":"GB"`)
+		assert.Contains(t, string(body), `"id":"1"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"data":%s}`, account)
+	})
+
+	out, err := form3ctl("-o", "table", "accounts", "create", "-id", "1", "-organisation-id", "2", "-country", "GB")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "ORGANISATION_ID")
+	assert.Contains(t, out, "GBDSC")
+
+	_, err = form3ctl("accounts", "create", "-id", "1", "-organisation-id", "2", "-country", "UK")
+	assert.EqualError(t, err, `invalid value "UK" for flag -country: invalid country "UK"`)
+}
+
+func TestRun_CreateFromFile(t *testing.T) {
+	mux, form3ctl, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"bank_id":"400300"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"data":%s}`, account)
+	})
+
+	dir, _ := ioutil.TempDir("", "form3ctl")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "account.json")
+	_ = ioutil.WriteFile(file, []byte(account), 0600)
+
+	_, err := form3ctl("accounts", "create", "-file", file)
+	assert.Nil(t, err)
+}
+
+func TestRun_ListWithFilterAsCSV(t *testing.T) {
+	mux, form3ctl, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GB", r.URL.Query().Get("filter[country]"))
+		assert.Equal(t, "first", r.URL.Query().Get("page[number]"))
+		_, _ = fmt.Fprintf(w, `{"data":[%s]}`, account)
+	})
+
+	out, err := form3ctl("-o", "csv", "accounts", "list", "-page", "first", "-size", "10", "-filter", "country=GB")
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join(columns, ",")+"\n1,2,3,GB,GBDSC,400300,GBP,,Jane\n", out)
+}
+
+func TestRun_DeleteFetchesVersion(t *testing.T) {
+	mux, form3ctl, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			_, _ = fmt.Fprintf(w, `{"data":%s}`, account)
+			return
+		}
+
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "3", r.URL.Query().Get("version"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := form3ctl("accounts", "delete", "1")
+	assert.Nil(t, err)
+}
+
+func TestRun_Purge(t *testing.T) {
+	mux, form3ctl, teardown := setup()
+	defer teardown()
+
+	remaining := []string{"1", "2"}
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		items := make([]string, len(remaining))
+		for i, id := range remaining {
+			items[i] = fmt.Sprintf(`{"id":"%s","type":"accounts","version":0}`, id)
+		}
+		_, _ = fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(items, ","))
+	})
+	mux.HandleFunc("/organisation/accounts/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		remaining = remaining[1:]
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := form3ctl("accounts", "purge")
+	assert.EqualError(t, err, "purge: refusing to delete accounts without -yes")
+
+	out, err := form3ctl("-o", "csv", "accounts", "purge", "-yes")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, strings.Count(out, "\n"))
+	assert.Empty(t, remaining)
+}
+
+func TestRun_Usage(t *testing.T) {
+	_, form3ctl, teardown := setup()
+	defer teardown()
+
+	_, err := form3ctl("accounts")
+	assert.Equal(t, errUsage, err)
+
+	_, err = form3ctl("accounts", "rename")
+	assert.Equal(t, errUsage, err)
+
+	_, err = form3ctl("-o", "xml", "accounts", "list")
+	assert.EqualError(t, err, `unknown output format "xml"`)
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "form3ctl")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	_ = ioutil.WriteFile(file, []byte("base_url: http://staging/v1\ntimeout: 10s\n"), 0600)
+
+	c, err := loadConfig(file, "", func(string) string { return "" })
+	assert.Nil(t, err)
+	client, err := c.client()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://staging/v1", client.BaseURL.String())
+
+	env := map[string]string{"FORM3_BASE_URL": "http://prod/v1", "FORM3_TIMEOUT": "500"}
+	c, err = loadConfig(file, "", func(k string) string { return env[k] })
+	assert.Nil(t, err)
+	assert.Equal(t, &form3.Config{BaseURL: "http://prod/v1", Timeout: 500 * time.Millisecond}, c.overrides)
+
+	c.override(&form3.Config{BaseURL: "http://flag/v1"})
+	client, err = c.client()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://flag/v1", client.BaseURL.String())
+
+	c, err = loadConfig("", "", func(string) string { return "" })
+	assert.Nil(t, err)
+	client, err = c.client()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/v1", client.BaseURL.String())
+
+	_ = ioutil.WriteFile(file, []byte("base_endpoint: http://staging/v1\n"), 0600)
+	_, err = loadConfig(file, "", func(string) string { return "" })
+	assert.Contains(t, err.Error(), "field base_endpoint not found")
+}
+
+func TestYAMLPrinter(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.Nil(t, (&yamlPrinter{w: out}).PrintOne(form3.MakeAccount("1", "2")))
+	assert.Equal(t, "id: \"1\"\norganisation_id: \"2\"\ntype: accounts\nversion: 0\n", out.String())
+
+	// collections stay lists whatever the number of accounts
+	out.Reset()
+	assert.Nil(t, (&yamlPrinter{w: out}).Print([]*form3.Account{form3.MakeAccount("1", "2")}))
+	assert.Equal(t, "- id: \"1\"\n  organisation_id: \"2\"\n  type: accounts\n  version: 0\n", out.String())
+
+	out.Reset()
+	assert.Nil(t, (&jsonPrinter{w: out}).Print(nil))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestRun_InvalidBaseURL(t *testing.T) {
+	err := run([]string{"-base-url", "localhost:8080", "accounts", "list"}, func(string) string { return "" }, ioutil.Discard)
+	assert.EqualError(t, err, `invalid base URL "localhost:8080": absolute http or https URL expected`)
+}
+
+func TestRun_ProductionProfile(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(http.StripPrefix("/v1", mux))
+	defer server.Close()
+
+	deleted := 0
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		if deleted > 0 {
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"data":[%s]}`, account)
+	})
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		deleted++
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	dir, _ := ioutil.TempDir("", "form3ctl")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	_ = ioutil.WriteFile(file, []byte(fmt.Sprintf(`default_profile: local
+profiles:
+  local:
+    base_url: http://localhost:8080/v1
+  production:
+    base_url: %s/v1
+    production: true
+    auth: {method: bearer, token: secret}
+`, server.URL)), 0600)
+
+	getenv := func(string) string { return "" }
+	err := run([]string{"-config", file, "-profile", "production", "accounts", "purge", "-yes"}, getenv, ioutil.Discard)
+	assert.True(t, errors.Is(err, form3.ErrDestructiveOperation))
+	assert.Equal(t, 0, deleted)
+
+	err = run([]string{"-config", file, "-profile", "production", "-allow-destructive", "accounts", "purge", "-yes"}, getenv, ioutil.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	err = run([]string{"-config", file, "-profile", "staging", "accounts", "list"}, getenv, ioutil.Discard)
+	assert.EqualError(t, err, `unknown profile "staging", configured: [local production]`)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"gopkg.in/yaml.v2"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+var columns = []string{"ID", "ORGANISATION_ID", "VERSION", "COUNTRY", "BANK_ID_CODE", "BANK_ID", "BASE_CURRENCY", "CUSTOMER_ID", "NAME"}
+
+// Prints a collection of accounts, always as a list, or a single one, as an object where the format has one.
+type printer interface {
+	Print(accounts []*form3.Account) error
+	PrintOne(account *form3.Account) error
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "json":
+		return &jsonPrinter{w: w}, nil
+	case "yaml":
+		return &yamlPrinter{w: w}, nil
+	case "table":
+		return &tablePrinter{w: w}, nil
+	case "csv":
+		return &csvPrinter{w: w}, nil
+	}
+
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+func row(a *form3.Account) []string {
+	attrs := a.Attributes
+	if attrs == nil {
+		attrs = &form3.AccountAttributes{}
+	}
+
+	return []string{
+		a.ID, a.OrganisationID, strconv.Itoa(a.Version), string(attrs.Country), string(attrs.BankIDCode),
+		attrs.BankID, string(attrs.BaseCurrency), attrs.CustomerID, attrs.Name,
+	}
+}
+
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p *jsonPrinter) Print(accounts []*form3.Account) error {
+	if accounts == nil {
+		accounts = []*form3.Account{}
+	}
+
+	return p.encode(accounts)
+}
+
+func (p *jsonPrinter) PrintOne(account *form3.Account) error {
+	return p.encode(account)
+}
+
+func (p *jsonPrinter) encode(v interface{}) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+type yamlPrinter struct {
+	w io.Writer
+}
+
+func (p *yamlPrinter) Print(accounts []*form3.Account) error {
+	if accounts == nil {
+		accounts = []*form3.Account{}
+	}
+
+	return p.encode(accounts)
+}
+
+func (p *yamlPrinter) PrintOne(account *form3.Account) error {
+	return p.encode(account)
+}
+
+func (p *yamlPrinter) encode(v interface{}) error {
+	// goes through JSON so keys follow the API field names
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.w.Write(out)
+	return err
+}
+
+type tablePrinter struct {
+	w io.Writer
+}
+
+func (p *tablePrinter) Print(accounts []*form3.Account) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	writeRow := func(cells []string) {
+		for i, c := range cells {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, c)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	writeRow(columns)
+	for _, a := range accounts {
+		writeRow(row(a))
+	}
+
+	return tw.Flush()
+}
+
+func (p *tablePrinter) PrintOne(account *form3.Account) error {
+	return p.Print([]*form3.Account{account})
+}
+
+type csvPrinter struct {
+	w io.Writer
+}
+
+func (p *csvPrinter) Print(accounts []*form3.Account) error {
+	cw := csv.NewWriter(p.w)
+	_ = cw.Write(columns)
+	for _, a := range accounts {
+		_ = cw.Write(row(a))
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (p *csvPrinter) PrintOne(account *form3.Account) error {
+	return p.Print([]*form3.Account{account})
+}
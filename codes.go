@@ -0,0 +1,362 @@
+package form3
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Returned when parsing an unknown code.
+type InvalidCodeError struct {
+	// Kind of the code, e.g. country
+	Kind  string
+	Value string
+}
+
+func (e *InvalidCodeError) Error() string {
+	return fmt.Sprintf("invalid %s %q", e.Kind, e.Value)
+}
+
+func normalizeCode(v string) string {
+	return strings.ToUpper(strings.TrimSpace(v))
+}
+
+// Implemented by the code types, so ValidateCodes finds them in any value.
+type code interface {
+	Valid() bool
+	codeKind() string
+}
+
+// Returns *InvalidCodeError for the first unknown BankIDCode, Country, Currency
+// or AccountClassification in v, walking pointers, structs, slices and maps.
+// Empty codes are accepted, as the fields are optional.
+func ValidateCodes(v interface{}) error {
+	return validateCodes(reflect.ValueOf(v))
+}
+
+func validateCodes(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateCodes(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// unexported fields cannot be read through Interface
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if err := validateCodes(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateCodes(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateCodes(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if c, ok := v.Interface().(code); ok && v.Len() > 0 && !c.Valid() {
+			return &InvalidCodeError{Kind: c.codeKind(), Value: v.String()}
+		}
+	}
+
+	return nil
+}
+
+// Identifies the national clearing system of the bank_id.
+type BankIDCode string
+
+const (
+	BankIDCodeGBDSC BankIDCode = "GBDSC"
+	BankIDCodeAUBSB BankIDCode = "AUBSB"
+	BankIDCodeBE    BankIDCode = "BE"
+	BankIDCodeCACPA BankIDCode = "CACPA"
+	BankIDCodeFR    BankIDCode = "FR"
+	BankIDCodeDEBLZ BankIDCode = "DEBLZ"
+	BankIDCodeGRBIC BankIDCode = "GRBIC"
+	BankIDCodeHKNCC BankIDCode = "HKNCC"
+	BankIDCodeITNCC BankIDCode = "ITNCC"
+	BankIDCodeLUNCC BankIDCode = "LUNCC"
+	BankIDCodeNLBIC BankIDCode = "NLBIC"
+	BankIDCodePLKNR BankIDCode = "PLKNR"
+	BankIDCodePTNCC BankIDCode = "PTNCC"
+	BankIDCodeESNCC BankIDCode = "ESNCC"
+	BankIDCodeCHBCC BankIDCode = "CHBCC"
+	BankIDCodeUSABA BankIDCode = "USABA"
+)
+
+// Country of each bank ID code.
+var bankIDCodeCountries = map[BankIDCode]Country{
+	BankIDCodeGBDSC: CountryGB,
+	BankIDCodeAUBSB: CountryAU,
+	BankIDCodeBE:    CountryBE,
+	BankIDCodeCACPA: CountryCA,
+	BankIDCodeFR:    CountryFR,
+	BankIDCodeDEBLZ: CountryDE,
+	BankIDCodeGRBIC: CountryGR,
+	BankIDCodeHKNCC: CountryHK,
+	BankIDCodeITNCC: CountryIT,
+	BankIDCodeLUNCC: CountryLU,
+	BankIDCodeNLBIC: CountryNL,
+	BankIDCodePLKNR: CountryPL,
+	BankIDCodePTNCC: CountryPT,
+	BankIDCodeESNCC: CountryES,
+	BankIDCodeCHBCC: CountryCH,
+	BankIDCodeUSABA: CountryUS,
+}
+
+func ParseBankIDCode(v string) (BankIDCode, error) {
+	code := BankIDCode(normalizeCode(v))
+	if !code.Valid() {
+		return "", &InvalidCodeError{Kind: "bank ID code", Value: v}
+	}
+
+	return code, nil
+}
+
+func (c BankIDCode) Valid() bool {
+	_, ok := bankIDCodeCountries[c]
+	return ok
+}
+
+// Country of the clearing system, empty for unknown codes.
+func (c BankIDCode) Country() Country {
+	return bankIDCodeCountries[c]
+}
+
+func (c BankIDCode) codeKind() string {
+	return "bank ID code"
+}
+
+func (c BankIDCode) String() string {
+	return string(c)
+}
+
+// Parses the code, so BankIDCode can be used as a flag.Value.
+func (c *BankIDCode) Set(v string) error {
+	code, err := ParseBankIDCode(v)
+	if err != nil {
+		return err
+	}
+	*c = code
+	return nil
+}
+
+// ISO 3166-1 alpha-2 country code.
+type Country string
+
+// Countries of the supported bank ID codes, see ParseCountry for the others.
+const (
+	CountryAU Country = "AU"
+	CountryBE Country = "BE"
+	CountryCA Country = "CA"
+	CountryCH Country = "CH"
+	CountryDE Country = "DE"
+	CountryES Country = "ES"
+	CountryFR Country = "FR"
+	CountryGB Country = "GB"
+	CountryGR Country = "GR"
+	CountryHK Country = "HK"
+	CountryIT Country = "IT"
+	CountryLU Country = "LU"
+	CountryNL Country = "NL"
+	CountryPL Country = "PL"
+	CountryPT Country = "PT"
+	CountryUS Country = "US"
+)
+
+var countries = codeSet(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+	BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+	CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+	DE DJ DK DM DO DZ
+	EC EE EG EH ER ES ET
+	FI FJ FK FM FO FR
+	GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+	HK HM HN HR HT HU
+	ID IE IL IM IN IO IQ IR IS IT
+	JE JM JO JP
+	KE KG KH KI KM KN KP KR KW KY KZ
+	LA LB LC LI LK LR LS LT LU LV LY
+	MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+	NA NC NE NF NG NI NL NO NP NR NU NZ
+	OM
+	PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+	QA
+	RE RO RS RU RW
+	SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+	TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+	UA UG UM US UY UZ
+	VA VC VE VG VI VN VU
+	WF WS
+	YE YT
+	ZA ZM ZW
+`)
+
+func ParseCountry(v string) (Country, error) {
+	country := Country(normalizeCode(v))
+	if !country.Valid() {
+		return "", &InvalidCodeError{Kind: "country", Value: v}
+	}
+
+	return country, nil
+}
+
+func (c Country) Valid() bool {
+	return countries[string(c)]
+}
+
+func (c Country) codeKind() string {
+	return "country"
+}
+
+func (c Country) String() string {
+	return string(c)
+}
+
+// Parses the code, so Country can be used as a flag.Value.
+func (c *Country) Set(v string) error {
+	country, err := ParseCountry(v)
+	if err != nil {
+		return err
+	}
+	*c = country
+	return nil
+}
+
+// ISO 4217 currency code.
+type Currency string
+
+// Currencies of the supported bank ID codes, see ParseCurrency for the others.
+const (
+	CurrencyAUD Currency = "AUD"
+	CurrencyCAD Currency = "CAD"
+	CurrencyCHF Currency = "CHF"
+	CurrencyEUR Currency = "EUR"
+	CurrencyGBP Currency = "GBP"
+	CurrencyHKD Currency = "HKD"
+	CurrencyPLN Currency = "PLN"
+	CurrencyUSD Currency = "USD"
+)
+
+// Active codes, plus recently withdrawn ones still found on existing accounts.
+var currencies = codeSet(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN
+	BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN BZD
+	CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK
+	DJF DKK DOP DZD
+	EGP ERN ETB EUR
+	FJD FKP
+	GBP GEL GHS GIP GMD GNF GTQ GYD
+	HKD HNL HRK HTG HUF
+	IDR ILS INR IQD IRR ISK
+	JMD JOD JPY
+	KES KGS KHR KMF KPW KRW KWD KYD KZT
+	LAK LBP LKR LRD LSL LYD
+	MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN
+	NAD NGN NIO NOK NPR NZD
+	OMR
+	PAB PEN PGK PHP PKR PLN PYG
+	QAR
+	RON RSD RUB RWF
+	SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL
+	THB TJS TMT TND TOP TRY TTD TWD TZS
+	UAH UGX USD USN UYI UYU UYW UZS
+	VED VES VND VUV
+	WST
+	XAF XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XTS XUA XXX
+	YER
+	ZAR ZMW ZWG ZWL
+`)
+
+func ParseCurrency(v string) (Currency, error) {
+	currency := Currency(normalizeCode(v))
+	if !currency.Valid() {
+		return "", &InvalidCodeError{Kind: "currency", Value: v}
+	}
+
+	return currency, nil
+}
+
+func (c Currency) Valid() bool {
+	return currencies[string(c)]
+}
+
+func (c Currency) codeKind() string {
+	return "currency"
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// Parses the code, so Currency can be used as a flag.Value.
+func (c *Currency) Set(v string) error {
+	currency, err := ParseCurrency(v)
+	if err != nil {
+		return err
+	}
+	*c = currency
+	return nil
+}
+
+// Whether the account is held by a person or a business.
+type AccountClassification string
+
+const (
+	AccountClassificationPersonal AccountClassification = "Personal"
+	AccountClassificationBusiness AccountClassification = "Business"
+)
+
+// Parses the classification ignoring case, e.g. personal.
+func ParseAccountClassification(v string) (AccountClassification, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "personal":
+		return AccountClassificationPersonal, nil
+	case "business":
+		return AccountClassificationBusiness, nil
+	}
+
+	return "", &InvalidCodeError{Kind: "account classification", Value: v}
+}
+
+func (c AccountClassification) Valid() bool {
+	return c == AccountClassificationPersonal || c == AccountClassificationBusiness
+}
+
+func (c AccountClassification) codeKind() string {
+	return "account classification"
+}
+
+func (c AccountClassification) String() string {
+	return string(c)
+}
+
+// Parses the classification, so AccountClassification can be used as a flag.Value.
+func (c *AccountClassification) Set(v string) error {
+	classification, err := ParseAccountClassification(v)
+	if err != nil {
+		return err
+	}
+	*c = classification
+	return nil
+}
+
+func codeSet(codes string) map[string]bool {
+	set := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		set[code] = true
+	}
+
+	return set
+}
@@ -0,0 +1,109 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestParseCodes(t *testing.T) {
+	code, err := ParseBankIDCode(" gbdsc ")
+	assert.Nil(t, err)
+	assert.Equal(t, BankIDCodeGBDSC, code)
+	assert.Equal(t, CountryGB, code.Country())
+
+	_, err = ParseBankIDCode("GBSDC")
+	assert.EqualError(t, err, `invalid bank ID code "GBSDC"`)
+
+	country, err := ParseCountry("fi")
+	assert.Nil(t, err)
+	assert.Equal(t, Country("FI"), country)
+	_, err = ParseCountry("UK")
+	assert.EqualError(t, err, `invalid country "UK"`)
+
+	currency, err := ParseCurrency("jpy")
+	assert.Nil(t, err)
+	assert.Equal(t, Currency("JPY"), currency)
+	_, err = ParseCurrency("GBX")
+	assert.EqualError(t, err, `invalid currency "GBX"`)
+
+	classification, err := ParseAccountClassification("business")
+	assert.Nil(t, err)
+	assert.Equal(t, AccountClassificationBusiness, classification)
+	_, err = ParseAccountClassification("corporate")
+	assert.EqualError(t, err, `invalid account classification "corporate"`)
+}
+
+func TestBankIDCodes_Countries(t *testing.T) {
+	for code, country := range bankIDCodeCountries {
+		assert.True(t, country.Valid(), "country of %s", code)
+	}
+	assert.Len(t, bankIDCodeCountries, 16)
+}
+
+func TestCodes_JSON(t *testing.T) {
+	data := []byte(`{"country":"XX","base_currency":"GBP","bank_id_code":"GBSDC"}`)
+
+	attrs := &AccountAttributes{}
+	assert.Nil(t, json.Unmarshal(data, attrs))
+	assert.Equal(t, Country("XX"), attrs.Country)
+	assert.Equal(t, BankIDCode("GBSDC"), attrs.BankIDCode)
+
+	encoded, err := json.Marshal(attrs)
+	assert.Nil(t, err)
+	assert.Contains(t, string(encoded), `"country":"XX"`)
+}
+
+func TestValidateCodes(t *testing.T) {
+	valid := &Account{Attributes: &AccountAttributes{Country: CountryGB, BaseCurrency: CurrencyGBP, BankIDCode: BankIDCodeGBDSC}}
+	assert.Nil(t, ValidateCodes(valid))
+	assert.Nil(t, ValidateCodes(&Account{}))
+	assert.Nil(t, ValidateCodes(nil))
+
+	valid.Attributes.PrivateIdentification = &PrivateIdentification{BirthCountry: "XX"}
+	assert.EqualError(t, ValidateCodes(valid), `invalid country "XX"`)
+
+	accounts := []*Account{{Attributes: &AccountAttributes{BankIDCode: "GBSDC"}}}
+	assert.EqualError(t, ValidateCodes(&accounts), `invalid bank ID code "GBSDC"`)
+
+	cop := &ConfirmationOfPayeeAttributes{AccountClassification: "personal"}
+	assert.EqualError(t, ValidateCodes(cop), `invalid account classification "personal"`)
+}
+
+func TestClient_StrictEnums(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{"id":"1","attributes":{"country":"XX"}}}`))
+	})
+
+	accounts := CreateAccountsService(client)
+	account := &Account{ID: "1", Attributes: &AccountAttributes{Country: "XX"}}
+
+	saved, _, err := accounts.Create(context.Background(), account)
+	assert.Nil(t, err)
+	assert.Equal(t, Country("XX"), saved.Attributes.Country)
+
+	client.strictEnums = true
+	_, _, err = accounts.Create(context.Background(), account)
+	assert.EqualError(t, err, `invalid country "XX"`)
+	assert.Equal(t, 1, requests)
+
+	account.Attributes.Country = CountryGB
+	_, resp, err := accounts.Create(context.Background(), account)
+	assert.EqualError(t, err, `invalid country "XX"`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestWithStrictEnums(t *testing.T) {
+	client, err := NewClient(WithStrictEnums(true))
+	assert.Nil(t, err)
+	assert.True(t, client.strictEnums)
+}
@@ -0,0 +1,140 @@
+package form3
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Client settings read from environment variables or a config file.
+type Config struct {
+	BaseURL               string        `yaml:"base_url"`
+	Timeout               time.Duration `yaml:"timeout"`
+	UserAgent             string        `yaml:"user_agent"`
+	DefaultOrganisationID string        `yaml:"organisation_id"`
+	RetryMaxAttempts      int           `yaml:"retry_max_attempts"`
+
+	// Profiles of the config file, not applied by WithConfig, see Select and WithProfile
+	Profiles `yaml:",inline"`
+}
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvBaseURL          = "FORM3_BASE_URL"
+	EnvTimeout          = "FORM3_TIMEOUT"
+	EnvUserAgent        = "FORM3_USER_AGENT"
+	EnvOrganisationID   = "FORM3_ORGANISATION_ID"
+	EnvRetryMaxAttempts = "FORM3_RETRY_MAX_ATTEMPTS"
+)
+
+// Reads settings from FORM3_* environment variables, unset ones are left empty.
+// FORM3_TIMEOUT is a duration such as 5s, plain numbers are milliseconds.
+func ConfigFromEnv() (*Config, error) {
+	return ConfigFromGetenv(os.Getenv)
+}
+
+// Reads settings as ConfigFromEnv does, looking the variables up with getenv.
+func ConfigFromGetenv(getenv func(string) string) (*Config, error) {
+	c := &Config{
+		BaseURL:               getenv(EnvBaseURL),
+		UserAgent:             getenv(EnvUserAgent),
+		DefaultOrganisationID: getenv(EnvOrganisationID),
+	}
+
+	if v := getenv(EnvTimeout); v != "" {
+		timeout, err := parseTimeout(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", EnvTimeout, err)
+		}
+		c.Timeout = timeout
+	}
+
+	if v := getenv(EnvRetryMaxAttempts); v != "" {
+		attempts, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", EnvRetryMaxAttempts, err)
+		}
+		c.RetryMaxAttempts = attempts
+	}
+
+	return c, nil
+}
+
+func parseTimeout(v string) (time.Duration, error) {
+	if ms, err := strconv.Atoi(v); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	return time.ParseDuration(v)
+}
+
+// Reads settings from a YAML or JSON file using the keys of Config and Profiles.
+func LoadConfigFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so both formats are read the same way
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("config %s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+// Settings of other take precedence over the ones of c, empty settings are ignored.
+func (c *Config) Merge(other *Config) *Config {
+	merged := *c
+	if other.BaseURL != "" {
+		merged.BaseURL = other.BaseURL
+	}
+	if other.Timeout != 0 {
+		merged.Timeout = other.Timeout
+	}
+	if other.UserAgent != "" {
+		merged.UserAgent = other.UserAgent
+	}
+	if other.DefaultOrganisationID != "" {
+		merged.DefaultOrganisationID = other.DefaultOrganisationID
+	}
+	if other.RetryMaxAttempts != 0 {
+		merged.RetryMaxAttempts = other.RetryMaxAttempts
+	}
+	if other.Default != "" {
+		merged.Default = other.Default
+	}
+	if len(other.Profiles.Profiles) > 0 {
+		merged.Profiles.Profiles = other.Profiles.Profiles
+	}
+
+	return &merged
+}
+
+// Applies the settings that are set.
+func WithConfig(c *Config) Option {
+	return func(o *ClientOptions) error {
+		if c.BaseURL != "" {
+			o.BaseEndpoint = c.BaseURL
+		}
+		if c.Timeout != 0 {
+			if err := WithTimeout(c.Timeout)(o); err != nil {
+				return err
+			}
+		}
+		if c.UserAgent != "" {
+			o.UserAgent = c.UserAgent
+		}
+		if c.DefaultOrganisationID != "" {
+			o.DefaultOrganisationID = c.DefaultOrganisationID
+		}
+		if c.RetryMaxAttempts != 0 {
+			o.Retry = &RetryPolicy{MaxAttempts: c.RetryMaxAttempts}
+		}
+		return nil
+	}
+}
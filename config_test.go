@@ -0,0 +1,78 @@
+package form3
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"FORM3_BASE_URL":           "https://api.example.com/v1",
+		"FORM3_TIMEOUT":            "1500",
+		"FORM3_USER_AGENT":         "agent",
+		"FORM3_ORGANISATION_ID":    "org",
+		"FORM3_RETRY_MAX_ATTEMPTS": "3",
+	}
+
+	c, err := ConfigFromGetenv(func(k string) string { return env[k] })
+	assert.Nil(t, err)
+	assert.Equal(t, &Config{
+		BaseURL:               "https://api.example.com/v1",
+		Timeout:               1500 * time.Millisecond,
+		UserAgent:             "agent",
+		DefaultOrganisationID: "org",
+		RetryMaxAttempts:      3,
+	}, c)
+
+	env["FORM3_TIMEOUT"] = "soon"
+	_, err = ConfigFromGetenv(func(k string) string { return env[k] })
+	assert.Contains(t, err.Error(), "FORM3_TIMEOUT: time: invalid duration")
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "form3")
+	defer os.RemoveAll(dir)
+
+	yamlFile := filepath.Join(dir, "config.yaml")
+	_ = ioutil.WriteFile(yamlFile, []byte("base_url: http://staging/v1\ntimeout: 10s\norganisation_id: org\n"), 0600)
+	c, err := LoadConfigFile(yamlFile)
+	assert.Nil(t, err)
+	assert.Equal(t, &Config{BaseURL: "http://staging/v1", Timeout: 10 * time.Second, DefaultOrganisationID: "org"}, c)
+
+	jsonFile := filepath.Join(dir, "config.json")
+	_ = ioutil.WriteFile(jsonFile, []byte(`{"base_url": "http://staging/v1", "retry_max_attempts": 2}`), 0600)
+	c, err = LoadConfigFile(jsonFile)
+	assert.Nil(t, err)
+	assert.Equal(t, &Config{BaseURL: "http://staging/v1", RetryMaxAttempts: 2}, c)
+
+	_ = ioutil.WriteFile(yamlFile, []byte("base_url: http://local/v1\ndefault_profile: staging\nprofiles:\n  staging: {base_url: http://staging/v1}\n"), 0600)
+	c, err = LoadConfigFile(yamlFile)
+	assert.Nil(t, err)
+	profile, err := c.Select("")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://staging/v1", profile.BaseURL)
+
+	_ = ioutil.WriteFile(yamlFile, []byte("base_endpoint: http://staging/v1\n"), 0600)
+	_, err = LoadConfigFile(yamlFile)
+	assert.NotNil(t, err)
+}
+
+func TestWithConfig(t *testing.T) {
+	file := &Config{BaseURL: "http://staging/v1", Timeout: 10 * time.Second, UserAgent: "agent"}
+	env := &Config{BaseURL: "https://prod/v1", RetryMaxAttempts: 3}
+
+	client, err := NewClient(WithConfig(file.Merge(env)))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://prod/v1", client.BaseURL.String())
+	assert.Equal(t, 10*time.Second, client.operationTimeout("accounts.ByID"))
+	assert.Equal(t, 15*time.Second, client.operationTimeout("accounts.List"))
+	assert.Equal(t, "agent", client.userAgent)
+	assert.IsType(t, &retryTransport{}, client.httpClient.Transport)
+
+	_, err = NewClient(WithConfig(&Config{BaseURL: "prod"}))
+	assert.EqualError(t, err, `invalid base URL "prod": absolute http or https URL expected`)
+}
@@ -0,0 +1,177 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	directDebitsBaseEndpoint = "/transaction/directdebits"
+)
+
+type DirectDebitsService service
+
+type DirectDebit struct {
+	ID             string                    `json:"id"`
+	OrganisationID string                    `json:"organisation_id"`
+	Type           string                    `json:"type"`
+	Attributes     *DirectDebitAttributes    `json:"attributes,omitempty"`
+	Version        int                       `json:"version"`
+	Relationships  *DirectDebitRelationships `json:"relationships,omitempty"`
+	CreatedOn      *time.Time                `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time                `json:"modified_on,omitempty"`
+}
+
+type DirectDebitAttributes struct {
+	Amount           string            `json:"amount"`
+	Currency         Currency          `json:"currency"`
+	Reference        string            `json:"reference"`
+	Scheme           string            `json:"scheme"`
+	ProcessingDate   string            `json:"processing_date,omitempty"`
+	BeneficiaryParty *DirectDebitParty `json:"beneficiary_party,omitempty"`
+	DebtorParty      *DirectDebitParty `json:"debtor_party,omitempty"`
+}
+
+type DirectDebitRelationships struct {
+	// Mandate the direct debit is collected under
+	Mandate *Relationship `json:"mandate,omitempty"`
+}
+
+type DirectDebitReturn struct {
+	ID         string                       `json:"id"`
+	Type       string                       `json:"type"`
+	Attributes *DirectDebitReturnAttributes `json:"attributes,omitempty"`
+	Version    int                          `json:"version"`
+}
+
+type DirectDebitReturnAttributes struct {
+	ReturnCode string `json:"return_code"`
+}
+
+// Build new direct debit collected under the mandate.
+func MakeDirectDebit(id, orgID, mandateID string) *DirectDebit {
+	return &DirectDebit{
+		ID:             id,
+		OrganisationID: orgID,
+		Type:           "direct_debits",
+		Relationships: &DirectDebitRelationships{
+			Mandate: makeRelationship(mandateID, "mandates"),
+		},
+	}
+}
+
+// Creates new direct debit.
+func (s *DirectDebitsService) Create(ctx context.Context, data *DirectDebit, opts ...CallOption) (*DirectDebit, *Response, error) {
+	if data == nil {
+		return nil, nil, ErrNilData
+	}
+
+	ctx = withOperation(ctx, "directdebits.Create", opts...)
+	ctx = withResourceID(ctx, data.ID)
+	client := s.client
+
+	orgID, err := client.stampOrganisation(data.OrganisationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if orgID != data.OrganisationID {
+		stamped := *data
+		stamped.OrganisationID = orgID
+		data = &stamped
+	}
+
+	req, err := client.POST(directDebitsBaseEndpoint, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directDebit := new(DirectDebit)
+	resp, err := client.Do(ctx, req, directDebit)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return directDebit, resp, nil
+}
+
+// Retrieves direct debit by ID.
+func (s *DirectDebitsService) ByID(ctx context.Context, id string, opts ...CallOption) (*DirectDebit, *Response, error) {
+	ctx = withOperation(ctx, "directdebits.ByID", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+	url := fmt.Sprintf("%s/%s", directDebitsBaseEndpoint, id)
+
+	req, err := client.GET(url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directDebit := new(DirectDebit)
+	resp, err := client.Do(ctx, req, directDebit)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if err := client.checkOrganisation(directDebit.OrganisationID); err != nil {
+		return nil, resp, err
+	}
+
+	return directDebit, resp, nil
+}
+
+// Get list of direct debits.
+func (s *DirectDebitsService) List(ctx context.Context, options *ListOptions, opts ...CallOption) ([]*DirectDebit, *Response, error) {
+	ctx = withOperation(ctx, "directdebits.List", opts...)
+	client := s.client
+
+	req, err := client.GET(addListOptions(directDebitsBaseEndpoint, client.scopeListOptions(options)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directDebits := new([]*DirectDebit)
+	resp, err := client.Do(ctx, req, directDebits)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, item := range *directDebits {
+		if err := client.checkOrganisation(item.OrganisationID); err != nil {
+			return nil, resp, err
+		}
+	}
+
+	return *directDebits, resp, nil
+}
+
+// Returns direct debit by ID with the given return code.
+func (s *DirectDebitsService) Return(ctx context.Context, id, returnID, returnCode string, opts ...CallOption) (*DirectDebitReturn, *Response, error) {
+	ctx = withOperation(ctx, "directdebits.Return", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+
+	if err := client.CheckDestructive("directdebits.Return"); err != nil {
+		return nil, nil, err
+	}
+	url := fmt.Sprintf("%s/%s/returns", directDebitsBaseEndpoint, id)
+
+	data := &DirectDebitReturn{
+		ID:         returnID,
+		Type:       "direct_debit_returns",
+		Attributes: &DirectDebitReturnAttributes{ReturnCode: returnCode},
+	}
+
+	req, err := client.POST(url, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directDebitReturn := new(DirectDebitReturn)
+	resp, err := client.Do(ctx, req, directDebitReturn)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return directDebitReturn, resp, nil
+}
@@ -0,0 +1,99 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func setupDirectDebits() (service *DirectDebitsService, mux *http.ServeMux, serverURL string, teardown func()) {
+	client, mux, serverURL, teardown := setup()
+	service = CreateDirectDebitsService(client)
+	return service, mux, serverURL, teardown
+}
+
+func TestDirectDebitsService_Create(t *testing.T) {
+	service, mux, _, teardown := setupDirectDebits()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/directdebits", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(
+			w,
+			`{"data":{"id":"1","organisation_id":"2","type":"direct_debits","version":0,`+
+				`"relationships":{"mandate":{"data":[{"id":"3","type":"mandates"}]}}}}`,
+		)
+	})
+
+	saved, resp, err := service.Create(context.Background(), MakeDirectDebit("1", "2", "3"))
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, MakeDirectDebit("1", "2", "3"), saved)
+}
+
+func TestDirectDebitsService_ByID(t *testing.T) {
+	service, mux, _, teardown := setupDirectDebits()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/directdebits/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"direct_debits","version":0}}`)
+	})
+
+	directDebit, resp, err := service.ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "1", directDebit.ID)
+}
+
+func TestDirectDebitsService_List(t *testing.T) {
+	service, mux, _, teardown := setupDirectDebits()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/directdebits", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"data":[{"id":"1","type":"direct_debits"}]}`)
+	})
+
+	list, _, err := service.List(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list))
+}
+
+func TestDirectDebitsService_Return(t *testing.T) {
+	service, mux, _, teardown := setupDirectDebits()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/directdebits/1/returns", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"data":{"id":"5","type":"direct_debit_returns","attributes":{"return_code":"0"},"version":0}}`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"5","type":"direct_debit_returns","attributes":{"return_code":"0"}}}`)
+	})
+
+	ret, resp, err := service.Return(context.Background(), "1", "5", "0")
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "0", ret.Attributes.ReturnCode)
+}
+
+func TestDirectDebitsService_ReturnError(t *testing.T) {
+	service, mux, _, teardown := setupDirectDebits()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/directdebits/1/returns", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = fmt.Fprint(w, `{"error_message":"direct debit already returned"}`)
+	})
+
+	ret, resp, err := service.Return(context.Background(), "1", "5", "0")
+	assert.Nil(t, ret)
+	assert.Equal(t, 409, resp.StatusCode)
+
+	errorResponse, ok := err.(*ErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, "direct debit already returned", errorResponse.Error())
+}
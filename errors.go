@@ -0,0 +1,141 @@
+package form3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrorResponse reports an error returned by the API.
+type ErrorResponse struct {
+	// HTTP response that caused this error
+	Response *http.Response `json:"-"`
+
+	ErrorMessage string `json:"error_message"`
+	ErrorCode    string `json:"error_code,omitempty"`
+}
+
+func (r *ErrorResponse) Error() string {
+	if r.ErrorMessage != "" {
+		return r.ErrorMessage
+	}
+
+	return fmt.Sprintf("%d %s", r.Response.StatusCode, http.StatusText(r.Response.StatusCode))
+}
+
+// Decodes the data member of a body into a target, e.g. one element at a time.
+type dataDecoder func(dec *json.Decoder) error
+
+// Streams the body in a single pass, decoding data into target and returning
+// *ErrorResponse if the body carries an error message or the status code is not successful.
+// Data of unsuccessful responses is skipped.
+func decodeResponse(r *http.Response, target interface{}) error {
+	errorResponse := &ErrorResponse{Response: r}
+	failed := r.StatusCode >= http.StatusBadRequest
+
+	err := decodeEnvelope(json.NewDecoder(r.Body), func(dec *json.Decoder, key string) error {
+		switch {
+		case key == "error_message":
+			return dec.Decode(&errorResponse.ErrorMessage)
+		case key == "error_code":
+			return dec.Decode(&errorResponse.ErrorCode)
+		case key == "data" && target != nil && !failed:
+			if decode, ok := target.(dataDecoder); ok {
+				return decode(dec)
+			}
+			return dec.Decode(target)
+		}
+
+		return skipValue(dec)
+	})
+
+	if errorResponse.ErrorMessage != "" || failed {
+		return errorResponse
+	}
+
+	// bodies of requests without target, e.g. deletes, are not checked
+	if err != nil && target != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Calls member for each key of the top level object, which decodes the value.
+// An empty body is not an error.
+func decodeEnvelope(dec *json.Decoder, member func(dec *json.Decoder, key string) error) error {
+	token, err := dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("response body is not a JSON object")
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := member(dec, token.(string)); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+// Skips the next value without keeping it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// Decodes the data array one element at a time, calling each after every element.
+func decodeArray(dec *json.Decoder, each func(dec *json.Decoder) error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("data is not a JSON array")
+	}
+
+	for dec.More() {
+		if err := each(dec); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+// Reports whether the error is, or wraps, an API error with 404 status code.
+func IsNotFound(err error) bool {
+	var errorResponse *ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Response != nil && errorResponse.Response.StatusCode == http.StatusNotFound
+}
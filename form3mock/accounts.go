@@ -0,0 +1,328 @@
+// Package form3mock provides fakes of the form3 service interfaces, so code
+// built on the library can be tested without HTTP.
+package form3mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Methods of form3.AccountsAPI, as recorded in Call.Method.
+const (
+	MethodCreate = "Create"
+	MethodByID   = "ByID"
+	MethodList   = "List"
+	MethodDelete = "Delete"
+)
+
+const defaultPageSize = 100
+
+// A recorded call, with the arguments the method takes.
+type Call struct {
+	Method  string
+	ID      string
+	Version int
+	Account *form3.Account
+	Options *form3.AccountListOptions
+}
+
+// Fake of form3.AccountsAPI keeping accounts in memory the way the API does:
+// duplicates and stale versions are rejected with 409, missing accounts with 404.
+//
+// The Func fields replace a method with canned behaviour. Calls are recorded
+// whatever serves them, and injected errors take precedence over both.
+type Accounts struct {
+	CreateFunc func(ctx context.Context, data *form3.Account) (*form3.Account, *form3.Response, error)
+	ByIDFunc   func(ctx context.Context, id string) (*form3.Account, *form3.Response, error)
+	ListFunc   func(ctx context.Context, options *form3.AccountListOptions) ([]*form3.Account, *form3.Response, error)
+	DeleteFunc func(ctx context.Context, id string, version int) (*form3.Response, error)
+
+	mu           sync.Mutex
+	accounts     map[string]*form3.Account
+	calls        []Call
+	errors       map[string][]injectedError
+	expectations []*Expectation
+	now          func() time.Time
+}
+
+type injectedError struct {
+	err  error
+	once bool
+}
+
+var _ form3.AccountsAPI = (*Accounts)(nil)
+
+// Creates fake holding the accounts.
+func NewAccounts(accounts ...*form3.Account) *Accounts {
+	f := &Accounts{
+		accounts: make(map[string]*form3.Account),
+		errors:   make(map[string][]injectedError),
+		now:      time.Now,
+	}
+	f.Seed(accounts...)
+
+	return f
+}
+
+// Stores copies of the accounts as they are, replacing ones with the same ID.
+func (f *Accounts) Seed(accounts ...*form3.Account) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, account := range accounts {
+		f.accounts[account.ID] = account.Clone()
+	}
+}
+
+// Makes every following call of the method fail with err, nil stops the failures.
+func (f *Accounts) InjectError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errors, method)
+		return
+	}
+	f.errors[method] = append(f.errors[method], injectedError{err: err})
+}
+
+// Makes the next call of the method fail with err, errors queue up when called again.
+func (f *Accounts) InjectErrorOnce(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors[method] = append(f.errors[method], injectedError{err: err, once: true})
+}
+
+// Records the call and returns the error injected for it, if any.
+func (f *Accounts) record(call Call) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, call)
+
+	injected := f.errors[call.Method]
+	if len(injected) == 0 {
+		return nil
+	}
+	if injected[0].once {
+		f.errors[call.Method] = injected[1:]
+	}
+
+	return injected[0].err
+}
+
+// Recorded calls in order.
+func (f *Accounts) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+// Recorded calls of the method in order.
+func (f *Accounts) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range f.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Copies of the accounts held, ordered by ID.
+func (f *Accounts) Accounts() []*form3.Account {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.sorted()
+}
+
+func (f *Accounts) sorted() []*form3.Account {
+	accounts := make([]*form3.Account, 0, len(f.accounts))
+	for _, account := range f.accounts {
+		accounts = append(accounts, account.Clone())
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+
+	return accounts
+}
+
+func (f *Accounts) Create(ctx context.Context, data *form3.Account, opts ...form3.CallOption) (*form3.Account, *form3.Response, error) {
+	if data == nil {
+		return nil, nil, form3.ErrNilData
+	}
+
+	if err := f.record(Call{Method: MethodCreate, ID: data.ID, Account: data.Clone()}); err != nil {
+		return nil, nil, err
+	}
+	if f.CreateFunc != nil {
+		return f.CreateFunc(ctx, data)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.accounts[data.ID]; ok {
+		return nil, response(http.StatusConflict), APIError(http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+	}
+
+	account := data.Clone()
+	now := f.now().UTC()
+	account.Version = 0
+	account.CreatedOn = &now
+	account.ModifiedOn = &now
+	f.accounts[account.ID] = account
+
+	return account.Clone(), response(http.StatusCreated), nil
+}
+
+func (f *Accounts) ByID(ctx context.Context, id string, opts ...form3.CallOption) (*form3.Account, *form3.Response, error) {
+	if err := f.record(Call{Method: MethodByID, ID: id}); err != nil {
+		return nil, nil, err
+	}
+	if f.ByIDFunc != nil {
+		return f.ByIDFunc(ctx, id)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	account, ok := f.accounts[id]
+	if !ok {
+		return nil, response(http.StatusNotFound), NotFound(id)
+	}
+
+	return account.Clone(), response(http.StatusOK), nil
+}
+
+// Filters by top level fields and attributes, e.g. organisation_id or country.
+// Pages are numbered from 0, first and last are supported too.
+func (f *Accounts) List(ctx context.Context, options *form3.AccountListOptions, opts ...form3.CallOption) ([]*form3.Account, *form3.Response, error) {
+	var recorded *form3.AccountListOptions
+	if options != nil {
+		copied := *options
+		recorded = &copied
+	}
+	if err := f.record(Call{Method: MethodList, Options: recorded}); err != nil {
+		return nil, nil, err
+	}
+	if f.ListFunc != nil {
+		return f.ListFunc(ctx, options)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matching []*form3.Account
+	for _, account := range f.sorted() {
+		if options == nil || matches(account, options.Filter) {
+			matching = append(matching, account)
+		}
+	}
+
+	if options == nil || (options.Number == "" && options.Size == 0) {
+		return matching, response(http.StatusOK), nil
+	}
+
+	size := options.Size
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	var page int
+	switch options.Number {
+	case "", "first":
+		page = 0
+	case "last":
+		page = (len(matching) - 1) / size
+		if page < 0 {
+			page = 0
+		}
+	default:
+		n, err := strconv.Atoi(options.Number)
+		if err != nil || n < 0 {
+			return nil, response(http.StatusBadRequest), APIError(http.StatusBadRequest, fmt.Sprintf("invalid page number %q", options.Number))
+		}
+		page = n
+	}
+
+	start, end := page*size, (page+1)*size
+	if start > len(matching) {
+		start = len(matching)
+	}
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	return matching[start:end], response(http.StatusOK), nil
+}
+
+func (f *Accounts) Delete(ctx context.Context, id string, version int, opts ...form3.CallOption) (*form3.Response, error) {
+	if err := f.record(Call{Method: MethodDelete, ID: id, Version: version}); err != nil {
+		return nil, err
+	}
+	if f.DeleteFunc != nil {
+		return f.DeleteFunc(ctx, id, version)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	account, ok := f.accounts[id]
+	if !ok {
+		return response(http.StatusNotFound), NotFound(id)
+	}
+	if account.Version != version {
+		return response(http.StatusConflict), APIError(http.StatusConflict, "invalid version")
+	}
+
+	delete(f.accounts, id)
+
+	return response(http.StatusNoContent), nil
+}
+
+// API error with the status, as returned by form3 services.
+func APIError(status int, message string) *form3.ErrorResponse {
+	return &form3.ErrorResponse{Response: &http.Response{StatusCode: status, Header: http.Header{}}, ErrorMessage: message}
+}
+
+// API error of a missing resource, form3.IsNotFound reports true for it.
+func NotFound(id string) *form3.ErrorResponse {
+	return APIError(http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+}
+
+func response(status int) *form3.Response {
+	return &form3.Response{Response: &http.Response{StatusCode: status, Status: fmt.Sprintf("%d %s", status, http.StatusText(status)), Header: http.Header{}}}
+}
+
+func matches(account *form3.Account, filter map[string]string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	data, _ := json.Marshal(account)
+	fields := map[string]interface{}{}
+	_ = json.Unmarshal(data, &fields)
+	attributes, _ := fields["attributes"].(map[string]interface{})
+
+	for k, v := range filter {
+		value, ok := fields[k]
+		if !ok {
+			value = attributes[k]
+		}
+		if fmt.Sprint(value) != v {
+			return false
+		}
+	}
+
+	return true
+}
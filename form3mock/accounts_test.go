@@ -0,0 +1,194 @@
+package form3mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestAccounts_Lifecycle(t *testing.T) {
+	fake := NewAccounts()
+	ctx := context.Background()
+
+	saved, resp, err := fake.Create(ctx, form3.MakeAccount("1", "org"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.NotNil(t, saved.CreatedOn)
+
+	_, resp, err = fake.Create(ctx, form3.MakeAccount("1", "org"))
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.EqualError(t, err, "Account cannot be created as it violates a duplicate constraint")
+
+	fetched, _, err := fake.ByID(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, saved, fetched)
+
+	_, err = fake.Delete(ctx, "1", 1)
+	assert.EqualError(t, err, "invalid version")
+
+	resp, err = fake.Delete(ctx, "1", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, _, err = fake.ByID(ctx, "1")
+	assert.True(t, form3.IsNotFound(err))
+	assert.EqualError(t, err, "record 1 does not exist")
+}
+
+func TestAccounts_ReturnsCopies(t *testing.T) {
+	account := form3.MakeAccount("1", "org")
+	fake := NewAccounts(account)
+	account.OrganisationID = "changed"
+
+	fetched, _, _ := fake.ByID(context.Background(), "1")
+	fetched.Version = 5
+
+	assert.Equal(t, "org", fake.Accounts()[0].OrganisationID)
+	assert.Equal(t, 0, fake.Accounts()[0].Version)
+
+	master := form3.MakeAccount("2", "org")
+	master.Relationships = &form3.AccountRelationships{MasterAccount: []form3.MasterAccountRelation{{ID: "1", Type: "accounts"}}}
+	_, _, _ = fake.Create(context.Background(), master)
+	master.Relationships.MasterAccount[0].ID = "changed"
+	fetched, _, _ = fake.ByID(context.Background(), "2")
+	assert.Equal(t, "1", fetched.Relationships.MasterAccount[0].ID)
+}
+
+func TestAccounts_CreateNilData(t *testing.T) {
+	fake := NewAccounts()
+	_, _, err := fake.Create(context.Background(), nil)
+	assert.Equal(t, form3.ErrNilData, err)
+	assert.Empty(t, fake.Calls())
+}
+
+func TestAccounts_ListPagesAndFilters(t *testing.T) {
+	fake := NewAccounts()
+	for i := 0; i < 5; i++ {
+		account := form3.MakeAccount(fmt.Sprint(i), "org")
+		account.Attributes = &form3.AccountAttributes{Country: form3.CountryGB}
+		if i%2 == 1 {
+			account.Attributes.Country = form3.CountryFR
+		}
+		fake.Seed(account)
+	}
+	fake.Seed(form3.MakeAccount("9", "other"))
+	ctx := context.Background()
+
+	all, _, err := fake.List(ctx, nil)
+	assert.Nil(t, err)
+	assert.Len(t, all, 6)
+
+	gb, _, _ := fake.List(ctx, &form3.ListOptions{Filter: map[string]string{"organisation_id": "org", "country": "GB"}})
+	assert.Equal(t, []string{"0", "2", "4"}, ids(gb))
+
+	page, _, _ := fake.List(ctx, &form3.ListOptions{Number: "1", Size: 4})
+	assert.Equal(t, []string{"4", "9"}, ids(page))
+
+	last, _, _ := fake.List(ctx, &form3.ListOptions{Number: "last", Size: 4})
+	assert.Equal(t, page, last)
+
+	beyond, _, _ := fake.List(ctx, &form3.ListOptions{Number: "3", Size: 4})
+	assert.Empty(t, beyond)
+
+	_, resp, err := fake.List(ctx, &form3.ListOptions{Number: "next"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.EqualError(t, err, `invalid page number "next"`)
+}
+
+func TestAccounts_InjectError(t *testing.T) {
+	fake := NewAccounts(form3.MakeAccount("1", "org"))
+	ctx := context.Background()
+	unavailable := APIError(http.StatusServiceUnavailable, "")
+
+	fake.InjectErrorOnce(MethodByID, unavailable)
+	_, _, err := fake.ByID(ctx, "1")
+	assert.Equal(t, unavailable, err)
+	assert.EqualError(t, err, "503 Service Unavailable")
+	_, _, err = fake.ByID(ctx, "1")
+	assert.Nil(t, err)
+
+	broken := errors.New("broken")
+	fake.InjectError(MethodDelete, broken)
+	for i := 0; i < 2; i++ {
+		_, err = fake.Delete(ctx, "1", 0)
+		assert.Equal(t, broken, err)
+	}
+	assert.Len(t, fake.Accounts(), 1)
+
+	fake.InjectError(MethodDelete, nil)
+	_, err = fake.Delete(ctx, "1", 0)
+	assert.Nil(t, err)
+}
+
+func TestAccounts_Funcs(t *testing.T) {
+	fake := NewAccounts()
+	fake.ByIDFunc = func(ctx context.Context, id string) (*form3.Account, *form3.Response, error) {
+		return form3.MakeAccount(id, "canned"), nil, nil
+	}
+
+	account, _, err := fake.ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "canned", account.OrganisationID)
+	assert.Equal(t, []Call{{Method: MethodByID, ID: "1"}}, fake.Calls())
+}
+
+func TestAccounts_RecordsCalls(t *testing.T) {
+	fake := NewAccounts()
+	ctx := context.Background()
+	options := &form3.ListOptions{Size: 10}
+
+	_, _, _ = fake.Create(ctx, form3.MakeAccount("1", "org"))
+	_, _, _ = fake.List(ctx, options)
+	_, _ = fake.Delete(ctx, "1", 0)
+	options.Size = 20
+
+	calls := fake.Calls()
+	assert.Len(t, calls, 3)
+	assert.Equal(t, "org", calls[0].Account.OrganisationID)
+	assert.Equal(t, 10, calls[1].Options.Size)
+	assert.Equal(t, []Call{{Method: MethodDelete, ID: "1"}}, fake.CallsTo(MethodDelete))
+}
+
+// Collects the errors reported to it.
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Helper() {}
+
+func TestAccounts_AssertExpectations(t *testing.T) {
+	fake := NewAccounts(form3.MakeAccount("1", "org"))
+	fake.Expect(MethodByID).WithID("1").Times(2)
+	fake.Expect(MethodList)
+	fake.Expect(MethodDelete).Times(0)
+
+	_, _, _ = fake.ByID(context.Background(), "1")
+	_, _, _ = fake.ByID(context.Background(), "2")
+
+	recorder := &recordingT{}
+	assert.False(t, fake.AssertExpectations(recorder))
+	assert.Equal(t, []string{
+		"form3mock: expected ByID(1) to be called 2 times, called 1",
+		"form3mock: expected List to be called",
+	}, recorder.errors)
+
+	_, _, _ = fake.ByID(context.Background(), "1")
+	_, _, _ = fake.List(context.Background(), nil)
+	assert.True(t, fake.AssertExpectations(t))
+}
+
+func ids(accounts []*form3.Account) []string {
+	ids := make([]string, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	return ids
+}
@@ -0,0 +1,81 @@
+package form3mock
+
+import "fmt"
+
+// Subset of *testing.T used to report unmet expectations.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	Helper()
+}
+
+// Expected calls of a method, at least one unless Times is set.
+type Expectation struct {
+	method string
+	id     string
+	times  int
+}
+
+// Restricts the expectation to calls with the resource ID.
+func (e *Expectation) WithID(id string) *Expectation {
+	e.id = id
+	return e
+}
+
+// Expects exactly n calls, 0 asserts the method is never called.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.id == "" {
+		return e.method
+	}
+	return fmt.Sprintf("%s(%s)", e.method, e.id)
+}
+
+func (e *Expectation) count(calls []Call) int {
+	n := 0
+	for _, call := range calls {
+		if call.Method == e.method && (e.id == "" || call.ID == e.id) {
+			n++
+		}
+	}
+	return n
+}
+
+// Expects calls of the method, checked by AssertExpectations.
+func (f *Accounts) Expect(method string) *Expectation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e := &Expectation{method: method, times: -1}
+	f.expectations = append(f.expectations, e)
+
+	return e
+}
+
+// Reports every expectation not met by the recorded calls, true when all are met.
+func (f *Accounts) AssertExpectations(t TestingT) bool {
+	t.Helper()
+
+	f.mu.Lock()
+	expectations := append([]*Expectation(nil), f.expectations...)
+	f.mu.Unlock()
+
+	calls := f.Calls()
+	ok := true
+	for _, e := range expectations {
+		n := e.count(calls)
+		switch {
+		case e.times < 0 && n == 0:
+			t.Errorf("form3mock: expected %s to be called", e)
+			ok = false
+		case e.times >= 0 && n != e.times:
+			t.Errorf("form3mock: expected %s to be called %d times, called %d", e, e.times, n)
+			ok = false
+		}
+	}
+
+	return ok
+}
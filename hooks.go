@@ -0,0 +1,94 @@
+package form3
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Request about to be sent, as seen by Hooks.
+type RequestInfo struct {
+	// API operation, e.g. accounts.Create, empty for requests sent with Client.Do directly
+	Operation string
+
+	// ID of the resource the operation acts on, empty for lists
+	ResourceID string
+
+	Method string
+	URL    string
+
+	// JSON body with the fields of Hooks.Redactor masked, nil when there is none
+	Payload []byte
+}
+
+// Outcome of a request, as seen by Hooks.
+type ResponseInfo struct {
+	RequestInfo
+
+	// Zero when no response was received
+	StatusCode int
+	Duration   time.Duration
+
+	// Error returned to the caller, including API errors
+	Err error
+}
+
+// Observes requests made by a client, e.g. for an audit trail.
+type Hooks struct {
+	// Called before a request is sent, an error fails the request without sending it
+	BeforeRequest func(ctx context.Context, info *RequestInfo) error
+
+	// Called once the response has been handled
+	AfterResponse func(ctx context.Context, info *ResponseInfo)
+
+	// Masks sensitive payload fields, DefaultRedactor when nil
+	Redactor *Redactor
+}
+
+// Calls hooks before and after requests.
+func WithHooks(hooks *Hooks) Option {
+	return func(o *ClientOptions) error {
+		o.Hooks = hooks
+		return nil
+	}
+}
+
+type resourceIDKey struct{}
+
+// Sets the ID of the resource the requests of ctx act on.
+func withResourceID(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		return nil
+	}
+
+	return context.WithValue(ctx, resourceIDKey{}, id)
+}
+
+// ID of the resource the request acts on, e.g. the account of accounts.Delete.
+func ResourceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(resourceIDKey{}).(string)
+	return id
+}
+
+func (h *Hooks) requestInfo(ctx context.Context, req *http.Request) *RequestInfo {
+	info := &RequestInfo{
+		Operation:  OperationFromContext(ctx),
+		ResourceID: ResourceIDFromContext(ctx),
+		Method:     req.Method,
+		URL:        req.URL.String(),
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			payload, _ := ioutil.ReadAll(body)
+			redactor := h.Redactor
+			if redactor == nil {
+				redactor = DefaultRedactor
+			}
+			info.Payload = redactor.Redact(payload)
+		}
+	}
+
+	return info
+}
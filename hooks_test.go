@@ -0,0 +1,103 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error_message": "invalid version"}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"data": {"id": "1"}}`)
+	}))
+	defer server.Close()
+
+	var before []*RequestInfo
+	var after []*ResponseInfo
+	client, err := NewClient(WithBaseURL(server.URL), WithHooks(&Hooks{
+		BeforeRequest: func(ctx context.Context, info *RequestInfo) error {
+			before = append(before, info)
+			if info.ResourceID == "blocked" {
+				return errors.New("blocked")
+			}
+			return nil
+		},
+		AfterResponse: func(ctx context.Context, info *ResponseInfo) {
+			after = append(after, info)
+		},
+	}))
+	assert.Nil(t, err)
+	service := CreateAccountsService(client)
+
+	account := MakeAccount("1", "2")
+	account.Attributes = &AccountAttributes{Name: "Jane", IBAN: "GB11NWBK40030041426819"}
+	_, _, err = service.Create(context.Background(), account)
+	assert.Nil(t, err)
+
+	_, err = service.Delete(context.Background(), "1", 3)
+	assert.EqualError(t, err, "invalid version")
+
+	_, err = service.Delete(context.Background(), "blocked", 0)
+	assert.EqualError(t, err, "blocked")
+	assert.Equal(t, 2, requests)
+
+	assert.Len(t, before, 3)
+	assert.Len(t, after, 2)
+
+	created := after[0]
+	assert.Equal(t, "accounts.Create", created.Operation)
+	assert.Equal(t, "1", created.ResourceID)
+	assert.Equal(t, "POST", created.Method)
+	assert.Equal(t, server.URL+"/organisation/accounts", created.URL)
+	assert.Equal(t, http.StatusCreated, created.StatusCode)
+	assert.True(t, created.Duration > 0)
+	assert.Nil(t, created.Err)
+	assert.Contains(t, string(created.Payload), `"name":"[REDACTED]"`)
+	assert.NotContains(t, string(created.Payload), "GB11NWBK40030041426819")
+
+	deleted := after[1]
+	assert.Equal(t, "accounts.Delete", deleted.Operation)
+	assert.Equal(t, "1", deleted.ResourceID)
+	assert.Equal(t, http.StatusConflict, deleted.StatusCode)
+	assert.EqualError(t, deleted.Err, "invalid version")
+	assert.Nil(t, deleted.Payload)
+}
+
+func TestCreate_NilData(t *testing.T) {
+	called := false
+	client, _ := NewClient(WithHooks(&Hooks{
+		BeforeRequest: func(ctx context.Context, info *RequestInfo) error {
+			called = true
+			return nil
+		},
+	}))
+	ctx := context.Background()
+
+	_, _, err := CreateAccountsService(client).Create(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	_, _, err = CreateMandatesService(client).Create(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	_, _, err = CreateDirectDebitsService(client).Create(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	_, _, err = CreateSubscriptionsService(client).Create(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	_, _, err = CreateOrganisationsService(client).Create(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	_, _, err = CreateOrganisationsService(client).Update(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	_, err = CreateOrganisationsService(client).Ensure(ctx, nil)
+	assert.Equal(t, ErrNilData, err)
+	assert.False(t, called)
+}
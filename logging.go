@@ -0,0 +1,359 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels, the values match log/slog levels.
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = -4
+	LevelInfo  LogLevel = 0
+	LevelWarn  LogLevel = 4
+	LevelError LogLevel = 8
+)
+
+func (l LogLevel) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	}
+
+	return "ERROR"
+}
+
+// Structured logger, args are alternating keys and values as in log/slog.
+// A *slog.Logger is adapted with
+//
+//	form3.LoggerFunc(func(ctx context.Context, l form3.LogLevel, msg string, args ...interface{}) {
+//		logger.Log(ctx, slog.Level(l), msg, args...)
+//	})
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, args ...interface{})
+}
+
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, args ...interface{})
+
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	f(ctx, level, msg, args...)
+}
+
+type textLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Creates logger writing lines of key=value pairs.
+func NewTextLogger(w io.Writer) Logger {
+	return &textLogger{w: w}
+}
+
+func (l *textLogger) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	line := &bytes.Buffer{}
+	fmt.Fprintf(line, "time=%s level=%s msg=%q", time.Now().Format(time.RFC3339), level, msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		value := fmt.Sprint(args[i+1])
+		if strings.ContainsAny(value, " \"=") || value == "" {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(line, " %v=%s", args[i], value)
+	}
+	line.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(line.Bytes())
+}
+
+type LoggingOptions struct {
+	Logger Logger
+
+	// Requests are logged at info, 4xx at warn, 5xx and failures at error; lower levels are dropped
+	Level LogLevel
+
+	// Logs request and response bodies, passed through the Redactor
+	LogBodies bool
+
+	// Bodies over this size are not logged, as a cut JSON body cannot be redacted;
+	// DefaultMaxLogBodyBytes when 0
+	MaxBodyBytes int
+
+	// Masks sensitive body fields, DefaultRedactor when nil
+	Redactor *Redactor
+}
+
+// JSON paths of sensitive fields of account payloads.
+var DefaultRedactPaths = []string{
+	"data.attributes.account_number",
+	"data.attributes.iban",
+	"data.attributes.name",
+	"data.attributes.alternative_names",
+	"data.attributes.secondary_identification",
+	"data.attributes.private_identification",
+	"data.attributes.organization_identification.actors",
+}
+
+// Query parameters of account lists holding sensitive values.
+var DefaultRedactQuery = []string{
+	"filter[account_number]",
+	"filter[iban]",
+	"filter[name]",
+	"filter[customer_id]",
+}
+
+const DefaultRedactMask = "[REDACTED]"
+
+const DefaultMaxLogBodyBytes = 64 << 10
+
+var DefaultRedactor = &Redactor{Paths: DefaultRedactPaths, Query: DefaultRedactQuery}
+
+// Masks JSON fields by path, e.g. "data.attributes.iban". Arrays along the
+// path are traversed, so the path applies to every account of a list, and
+// "*" matches any key. Whole objects are masked when the path ends at them.
+type Redactor struct {
+	Paths []string
+
+	// Names of URL query parameters whose values are masked, e.g. "filter[iban]"
+	Query []string
+
+	// Replaces masked values, DefaultRedactMask when empty
+	Mask string
+}
+
+// Returns the body with masked fields; bodies that are not JSON are returned as they are.
+func (r *Redactor) Redact(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return body
+	}
+
+	for _, path := range r.Paths {
+		v = redactPath(v, strings.Split(path, "."), r.mask())
+	}
+
+	redacted, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+
+	return redacted
+}
+
+// Returns the URL with values of the Query parameters masked.
+func (r *Redactor) RedactURL(u *url.URL) string {
+	if u.RawQuery == "" || len(r.Query) == 0 {
+		return u.String()
+	}
+
+	parts := strings.Split(u.RawQuery, "&")
+	for i, part := range parts {
+		name := strings.SplitN(part, "=", 2)[0]
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		for _, q := range r.Query {
+			if name == q {
+				parts[i] = strings.SplitN(part, "=", 2)[0] + "=" + url.QueryEscape(r.mask())
+				break
+			}
+		}
+	}
+
+	redacted := *u
+	redacted.RawQuery = strings.Join(parts, "&")
+	return redacted.String()
+}
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return DefaultRedactMask
+	}
+
+	return r.Mask
+}
+
+func redactPath(v interface{}, path []string, mask string) interface{} {
+	if len(path) == 0 {
+		if v == nil {
+			return nil
+		}
+		return mask
+	}
+
+	switch value := v.(type) {
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactPath(item, path, mask)
+		}
+	case map[string]interface{}:
+		for k, item := range value {
+			if path[0] == "*" || path[0] == k {
+				value[k] = redactPath(item, path[1:], mask)
+			}
+		}
+	}
+
+	return v
+}
+
+type loggingTransport struct {
+	next         http.RoundTripper
+	options      LoggingOptions
+	redactor     *Redactor
+	maxBodyBytes int
+	now          func() time.Time
+}
+
+func newLoggingTransport(next http.RoundTripper, options *LoggingOptions) *loggingTransport {
+	t := &loggingTransport{next: next, options: *options, redactor: options.Redactor, maxBodyBytes: options.MaxBodyBytes, now: time.Now}
+	if t.redactor == nil {
+		t.redactor = DefaultRedactor
+	}
+	if t.maxBodyBytes <= 0 {
+		t.maxBodyBytes = DefaultMaxLogBodyBytes
+	}
+
+	return t
+}
+
+// Logs the request once the response arrives, or once its body is closed when bodies are logged,
+// so the body streams to the caller and only a bounded copy is kept for the log.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := t.now()
+	resp, err := t.next.RoundTrip(req)
+	duration := t.now().Sub(started)
+
+	args := []interface{}{
+		"method", req.Method,
+		"url", t.redactor.RedactURL(req.URL),
+		"duration", duration,
+	}
+	if operation := OperationFromContext(req.Context()); operation != "" {
+		args = append(args, "operation", operation)
+	}
+
+	level := LevelInfo
+	if err != nil {
+		level = LevelError
+		args = append(args, "error", err.Error())
+	} else {
+		args = append(args, "status", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			level = LevelError
+		} else if resp.StatusCode >= http.StatusBadRequest {
+			level = LevelWarn
+		}
+	}
+
+	if level < t.options.Level {
+		return resp, err
+	}
+
+	if !t.options.LogBodies {
+		t.options.Logger.Log(req.Context(), level, "form3 request", args...)
+		return resp, err
+	}
+
+	args = append(args, "request_body", t.requestBody(req))
+	if resp == nil || resp.Body == nil {
+		t.options.Logger.Log(req.Context(), level, "form3 request", args...)
+		return resp, err
+	}
+
+	captured := &cappedBuffer{max: t.maxBodyBytes}
+	resp.Body = &loggedBody{
+		Reader: io.TeeReader(resp.Body, captured),
+		body:   resp.Body,
+		log: func() {
+			args = append(args, "response_body", t.formatBody(captured))
+			t.options.Logger.Log(req.Context(), level, "form3 request", args...)
+		},
+	}
+
+	return resp, err
+}
+
+// Body of the request read through GetBody, so the request itself is not changed.
+func (t *loggingTransport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if req.GetBody == nil {
+		return "[body cannot be read twice, not logged]"
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Sprintf("[%v]", err)
+	}
+	defer body.Close()
+
+	captured := &cappedBuffer{max: t.maxBodyBytes}
+	if _, err := io.Copy(captured, body); err != nil {
+		return fmt.Sprintf("[%v]", err)
+	}
+
+	return t.formatBody(captured)
+}
+
+func (t *loggingTransport) formatBody(b *cappedBuffer) string {
+	if b.truncated {
+		return fmt.Sprintf("[body over %d bytes, not logged]", b.max)
+	}
+
+	return string(t.redactor.Redact(b.buf.Bytes()))
+}
+
+// Keeps the first max bytes written, accepting and dropping the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// Response body logging the request when it is closed.
+type loggedBody struct {
+	io.Reader
+	body io.ReadCloser
+	once sync.Once
+	log  func()
+}
+
+func (b *loggedBody) Close() error {
+	err := b.body.Close()
+	b.once.Do(b.log)
+	return err
+}
@@ -0,0 +1,184 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type logEntry struct {
+	level LogLevel
+	msg   string
+	args  map[string]interface{}
+}
+
+type recordingLogger struct {
+	entries []logEntry
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	entry := logEntry{level: level, msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		entry.args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+// Responds with the body, keeping the last request.
+type bodyTransport struct {
+	body string
+	last *http.Request
+}
+
+func (b *bodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.last = req
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(b.body)), Header: http.Header{}}, nil
+}
+
+func setupLogging(options *LoggingOptions) (service *AccountsService, mux *http.ServeMux, teardown func()) {
+	client, mux, _, teardown := setup()
+	logged := CreateClient(&ClientOptions{Timeout: 3000, Logging: options})
+	logged.BaseURL = client.BaseURL
+
+	return CreateAccountsService(logged), mux, teardown
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	body := `{"data":[{"id":"1","attributes":{"iban":"GB33BUKB20201555555555","bank_id":"400300",` +
+		`"private_identification":{"birth_date":"2017-07-23","address":["10 Avenue"]}}},` +
+		`{"id":"2","attributes":{"account_number":41426819}}]}`
+
+	assert.Equal(
+		t,
+		`{"data":[{"attributes":{"bank_id":"400300","iban":"[REDACTED]","private_identification":"[REDACTED]"},"id":"1"},`+
+			`{"attributes":{"account_number":"[REDACTED]"},"id":"2"}]}`,
+		string(DefaultRedactor.Redact([]byte(body))),
+	)
+}
+
+func TestRedactor_CustomPaths(t *testing.T) {
+	redactor := &Redactor{Paths: []string{"data.*.birth_date", "error_message"}, Mask: "***"}
+
+	assert.Equal(
+		t,
+		`{"data":{"a":{"birth_date":"***"},"b":{"birth_date":"***","city":"London"}},"error_message":"***"}`,
+		string(redactor.Redact([]byte(`{"data":{"a":{"birth_date":"x"},"b":{"birth_date":"y","city":"London"}},"error_message":"z"}`))),
+	)
+	assert.Equal(t, "not json", string(redactor.Redact([]byte("not json"))))
+}
+
+func TestLogging_LogsRequests(t *testing.T) {
+	logger := &recordingLogger{}
+	service, mux, teardown := setupLogging(&LoggingOptions{Logger: logger, LogBodies: true})
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		testBody(t, r, `{"data":{"id":"1","organisation_id":"2","type":"accounts","attributes":{"country":"GB",`+
+			`"base_currency":"","bank_id":"","bank_id_code":"","account_number":"","bic":"","iban":"GB1","customer_id":"","name":""},"version":0}}`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","attributes":{"iban":"GB1"}}}`)
+	})
+
+	account := MakeAccount("1", "2")
+	account.Attributes = &AccountAttributes{Country: "GB", IBAN: "GB1"}
+	saved, _, err := service.Create(context.Background(), account)
+	assert.Nil(t, err)
+	assert.Equal(t, "GB1", saved.Attributes.IBAN, "response body is still decoded after logging")
+
+	assert.Equal(t, 1, len(logger.entries))
+	entry := logger.entries[0]
+	assert.Equal(t, LevelInfo, entry.level)
+	assert.Equal(t, "POST", entry.args["method"])
+	assert.Equal(t, 201, entry.args["status"])
+	assert.Equal(t, "accounts.Create", entry.args["operation"])
+	assert.NotNil(t, entry.args["duration"])
+	assert.Contains(t, entry.args["request_body"], `"iban":"[REDACTED]"`)
+	assert.Contains(t, entry.args["request_body"], `"country":"GB"`)
+	assert.Equal(t, `{"data":{"attributes":{"iban":"[REDACTED]"},"id":"1"}}`, entry.args["response_body"])
+}
+
+func TestLogging_Levels(t *testing.T) {
+	logger := &recordingLogger{}
+	service, mux, teardown := setupLogging(&LoggingOptions{Logger: logger, Level: LevelWarn})
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1"}}`)
+	})
+	mux.HandleFunc("/organisation/accounts/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/organisation/accounts/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		_, _, _ = service.ByID(context.Background(), id)
+	}
+
+	assert.Equal(t, 2, len(logger.entries))
+	assert.Equal(t, LevelWarn, logger.entries[0].level)
+	assert.Equal(t, LevelError, logger.entries[1].level)
+	assert.Nil(t, logger.entries[0].args["response_body"], "bodies are not logged by default")
+}
+
+func TestTextLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	NewTextLogger(out).Log(context.Background(), LevelWarn, "form3 request", "method", "GET", "status", 404, "url", "a b")
+
+	assert.Regexp(t, `^time=\S+ level=WARN msg="form3 request" method=GET status=404 url="a b"\n$`, out.String())
+}
+
+func TestLogging_BodiesStream(t *testing.T) {
+	logger := &recordingLogger{}
+	large := `{"data":"` + strings.Repeat("x", 64) + `"}`
+	transport := newLoggingTransport(&bodyTransport{body: large}, &LoggingOptions{Logger: logger, LogBodies: true, MaxBodyBytes: 32})
+
+	req, _ := http.NewRequest("POST", "http://localhost/v1/organisation/accounts", strings.NewReader(`{"id":"1"}`))
+	body := req.Body
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, body, req.Body, "request is not modified")
+	assert.Equal(t, 0, len(logger.entries), "logged once the body is closed")
+
+	read, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, large, string(read))
+	_ = resp.Body.Close()
+	_ = resp.Body.Close()
+
+	assert.Equal(t, 1, len(logger.entries))
+	assert.Equal(t, `{"id":"1"}`, logger.entries[0].args["request_body"])
+	assert.Equal(t, "[body over 32 bytes, not logged]", logger.entries[0].args["response_body"])
+}
+
+func TestRedactor_RedactURL(t *testing.T) {
+	u, _ := url.Parse("http://localhost/v1/organisation/accounts?page[number]=0&filter[iban]=GB33BUKB&filter%5Bcustomer_id%5D=c-1&filter[country]=GB")
+
+	assert.Equal(
+		t,
+		"http://localhost/v1/organisation/accounts?page[number]=0&filter[iban]=%5BREDACTED%5D&filter%5Bcustomer_id%5D=%5BREDACTED%5D&filter[country]=GB",
+		DefaultRedactor.RedactURL(u),
+	)
+	assert.Equal(t, "http://localhost/v1?filter[iban]=x", (&Redactor{}).RedactURL(&url.URL{Scheme: "http", Host: "localhost", Path: "/v1", RawQuery: "filter[iban]=x"}))
+}
+
+func TestLogging_RedactsURL(t *testing.T) {
+	logger := &recordingLogger{}
+	service, mux, teardown := setupLogging(&LoggingOptions{Logger: logger})
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	_, _, _ = service.List(context.Background(), &ListOptions{Filter: map[string]string{"account_number": "41426819"}})
+	assert.Equal(t, 1, len(logger.entries))
+	assert.NotContains(t, logger.entries[0].args["url"], "41426819")
+	assert.Contains(t, logger.entries[0].args["url"], "filter[account_number]=%5BREDACTED%5D")
+}
@@ -0,0 +1,203 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	mandatesBaseEndpoint = "/transaction/mandates"
+)
+
+type MandatesService service
+
+type Mandate struct {
+	ID             string                `json:"id"`
+	OrganisationID string                `json:"organisation_id"`
+	Type           string                `json:"type"`
+	Attributes     *MandateAttributes    `json:"attributes,omitempty"`
+	Version        int                   `json:"version"`
+	Relationships  *MandateRelationships `json:"relationships,omitempty"`
+	CreatedOn      *time.Time            `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time            `json:"modified_on,omitempty"`
+}
+
+type MandateAttributes struct {
+	Reference        string            `json:"reference"`
+	Scheme           string            `json:"scheme"`
+	ProcessingDate   string            `json:"processing_date,omitempty"`
+	Status           string            `json:"status,omitempty"`
+	BeneficiaryParty *DirectDebitParty `json:"beneficiary_party,omitempty"`
+	DebtorParty      *DirectDebitParty `json:"debtor_party,omitempty"`
+}
+
+// Party taking part in a mandate or a direct debit.
+type DirectDebitParty struct {
+	AccountName   string     `json:"account_name"`
+	AccountNumber string     `json:"account_number"`
+	BankID        string     `json:"bank_id"`
+	BankIDCode    BankIDCode `json:"bank_id_code"`
+}
+
+type MandateRelationships struct {
+	// Account the mandate is set up for
+	Account *Relationship `json:"account,omitempty"`
+}
+
+type MandateCancellation struct {
+	ID         string                         `json:"id"`
+	Type       string                         `json:"type"`
+	Attributes *MandateCancellationAttributes `json:"attributes,omitempty"`
+	Version    int                            `json:"version"`
+}
+
+type MandateCancellationAttributes struct {
+	Reason string `json:"reason"`
+}
+
+// Build new mandate referencing an existing account.
+func MakeMandate(id, orgID, accountID string) *Mandate {
+	return &Mandate{
+		ID:             id,
+		OrganisationID: orgID,
+		Type:           "mandates",
+		Relationships: &MandateRelationships{
+			Account: makeRelationship(accountID, "accounts"),
+		},
+	}
+}
+
+// ID of the account the mandate is set up for.
+func (m *Mandate) AccountID() string {
+	if m.Relationships == nil {
+		return ""
+	}
+
+	return m.Relationships.Account.ID()
+}
+
+// Creates new mandate.
+func (s *MandatesService) Create(ctx context.Context, data *Mandate, opts ...CallOption) (*Mandate, *Response, error) {
+	if data == nil {
+		return nil, nil, ErrNilData
+	}
+
+	ctx = withOperation(ctx, "mandates.Create", opts...)
+	ctx = withResourceID(ctx, data.ID)
+	client := s.client
+
+	orgID, err := client.stampOrganisation(data.OrganisationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if orgID != data.OrganisationID {
+		stamped := *data
+		stamped.OrganisationID = orgID
+		data = &stamped
+	}
+
+	req, err := client.POST(mandatesBaseEndpoint, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandate := new(Mandate)
+	resp, err := client.Do(ctx, req, mandate)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return mandate, resp, nil
+}
+
+// Retrieves mandate by ID.
+func (s *MandatesService) ByID(ctx context.Context, id string, opts ...CallOption) (*Mandate, *Response, error) {
+	ctx = withOperation(ctx, "mandates.ByID", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+	url := fmt.Sprintf("%s/%s", mandatesBaseEndpoint, id)
+
+	req, err := client.GET(url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandate := new(Mandate)
+	resp, err := client.Do(ctx, req, mandate)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if err := client.checkOrganisation(mandate.OrganisationID); err != nil {
+		return nil, resp, err
+	}
+
+	return mandate, resp, nil
+}
+
+// Get list of mandates.
+func (s *MandatesService) List(ctx context.Context, options *ListOptions, opts ...CallOption) ([]*Mandate, *Response, error) {
+	ctx = withOperation(ctx, "mandates.List", opts...)
+	client := s.client
+
+	req, err := client.GET(addListOptions(mandatesBaseEndpoint, client.scopeListOptions(options)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandates := new([]*Mandate)
+	resp, err := client.Do(ctx, req, mandates)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, item := range *mandates {
+		if err := client.checkOrganisation(item.OrganisationID); err != nil {
+			return nil, resp, err
+		}
+	}
+
+	return *mandates, resp, nil
+}
+
+// Cancels mandate by ID.
+func (s *MandatesService) Cancel(ctx context.Context, id, cancellationID, reason string, opts ...CallOption) (*MandateCancellation, *Response, error) {
+	ctx = withOperation(ctx, "mandates.Cancel", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+
+	if err := client.CheckDestructive("mandates.Cancel"); err != nil {
+		return nil, nil, err
+	}
+	url := fmt.Sprintf("%s/%s/cancellations", mandatesBaseEndpoint, id)
+
+	data := &MandateCancellation{
+		ID:         cancellationID,
+		Type:       "mandate_cancellations",
+		Attributes: &MandateCancellationAttributes{Reason: reason},
+	}
+
+	req, err := client.POST(url, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cancellation := new(MandateCancellation)
+	resp, err := client.Do(ctx, req, cancellation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cancellation, resp, nil
+}
+
+// Retrieves the account the mandate is set up for.
+func (s *MandatesService) Account(ctx context.Context, mandate *Mandate, opts ...CallOption) (*Account, *Response, error) {
+	if mandate == nil {
+		return nil, nil, ErrNilData
+	}
+
+	accounts := &AccountsService{client: s.client}
+	return accounts.ByID(ctx, mandate.AccountID(), opts...)
+}
@@ -0,0 +1,108 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func setupMandates() (service *MandatesService, mux *http.ServeMux, serverURL string, teardown func()) {
+	client, mux, serverURL, teardown := setup()
+	service = CreateMandatesService(client)
+	return service, mux, serverURL, teardown
+}
+
+func TestMakeMandate(t *testing.T) {
+	mandate := MakeMandate("1", "2", "3")
+	assert.Equal(t, "mandates", mandate.Type)
+	assert.Equal(t, "3", mandate.AccountID())
+}
+
+func TestMandatesService_Create(t *testing.T) {
+	service, mux, _, teardown := setupMandates()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/mandates", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"data":{"id":"1","organisation_id":"2","type":"mandates","version":0,`+
+			`"relationships":{"account":{"data":[{"id":"3","type":"accounts"}]}}}}`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(
+			w,
+			`{"data":{"id":"1","organisation_id":"2","type":"mandates","version":0,`+
+				`"relationships":{"account":{"data":[{"id":"3","type":"accounts"}]}}}}`,
+		)
+	})
+
+	saved, resp, err := service.Create(context.Background(), MakeMandate("1", "2", "3"))
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, MakeMandate("1", "2", "3"), saved)
+}
+
+func TestMandatesService_ByID(t *testing.T) {
+	service, mux, _, teardown := setupMandates()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/mandates/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"mandates","version":0}}`)
+	})
+
+	mandate, resp, err := service.ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "1", mandate.ID)
+}
+
+func TestMandatesService_List(t *testing.T) {
+	service, mux, _, teardown := setupMandates()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/mandates", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		assert.Equal(t, "2", r.URL.Query().Get("page[number]"))
+		assert.Equal(t, "10", r.URL.Query().Get("page[size]"))
+		_, _ = fmt.Fprint(w, `{"data":[{"id":"1","type":"mandates"},{"id":"2","type":"mandates"}]}`)
+	})
+
+	list, _, err := service.List(context.Background(), &ListOptions{Number: "2", Size: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+}
+
+func TestMandatesService_Cancel(t *testing.T) {
+	service, mux, _, teardown := setupMandates()
+	defer teardown()
+
+	mux.HandleFunc("/transaction/mandates/1/cancellations", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"data":{"id":"5","type":"mandate_cancellations","attributes":{"reason":"closed"},"version":0}}`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"5","type":"mandate_cancellations","attributes":{"reason":"closed"}}}`)
+	})
+
+	cancellation, resp, err := service.Cancel(context.Background(), "1", "5", "closed")
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "closed", cancellation.Attributes.Reason)
+}
+
+func TestMandatesService_Account(t *testing.T) {
+	service, mux, _, teardown := setupMandates()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts/3", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"data":{"id":"3","organisation_id":"2","type":"accounts","version":0}}`)
+	})
+
+	account, _, err := service.Account(context.Background(), MakeMandate("1", "2", "3"))
+	assert.Nil(t, err)
+	assert.Equal(t, MakeAccount("3", "2"), account)
+
+	_, _, err = service.Account(context.Background(), nil)
+	assert.Equal(t, ErrNilData, err)
+}
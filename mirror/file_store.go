@@ -0,0 +1,92 @@
+package mirror
+
+import (
+	"encoding/json"
+	"github.com/ig-hit/form3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const accountFileExt = ".json"
+
+type fileStore struct {
+	dir string
+
+	// serves reads, files are only read on open
+	*memoryStore
+}
+
+// Creates store keeping every account in a JSON file of the directory,
+// accounts already stored in the directory are loaded.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &fileStore{dir: dir, memoryStore: newMemoryStore()}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != accountFileExt {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		account := new(form3.Account)
+		if err := json.Unmarshal(data, account); err != nil {
+			return nil, err
+		}
+		_ = s.memoryStore.Put(account)
+	}
+
+	return s, nil
+}
+
+func (s *fileStore) path(id string) string {
+	// IDs are UUIDs, path separators are replaced to keep files inside the directory
+	return filepath.Join(s.dir, strings.Replace(id, string(filepath.Separator), "_", -1)+accountFileExt)
+}
+
+func (s *fileStore) Put(accounts ...*form3.Account) error {
+	for _, account := range accounts {
+		data, err := json.Marshal(account)
+		if err != nil {
+			return err
+		}
+
+		// written to a temporary file first so a crash never leaves a partial account
+		tmp := s.path(account.ID) + ".tmp"
+		if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, s.path(account.ID)); err != nil {
+			return err
+		}
+
+		_ = s.memoryStore.Put(account)
+	}
+
+	return nil
+}
+
+func (s *fileStore) Delete(ids ...string) error {
+	for _, id := range ids {
+		if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		_ = s.memoryStore.Delete(id)
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+// Package mirror keeps a local copy of the accounts of an organisation,
+// so read-heavy consumers query the local store instead of the API.
+package mirror
+
+import (
+	"context"
+	"github.com/ig-hit/form3"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxStaleness     = time.Minute
+	defaultPageSize         = 100
+	defaultFullSyncInterval = time.Hour
+
+	// List filter of accounts modified at or after the RFC 3339 time. An API ignoring it
+	// is detected by older accounts in the result, Refresh then always lists all accounts.
+	modifiedFromFilter = "modified_on_from"
+)
+
+type Options struct {
+	// Queries refresh the store first when the last sync is older, 1 minute by default
+	MaxStaleness time.Duration
+
+	// Size of the pages requested from List, 100 by default
+	PageSize int
+
+	// Refresh lists only accounts modified since the previous sync, which does not reveal
+	// deleted ones, so all accounts are listed again at this interval, 1 hour by default
+	FullSyncInterval time.Duration
+}
+
+// Mirror syncs accounts of an organisation into the store and queries them locally.
+type Mirror struct {
+	service          form3.AccountsAPI
+	store            Store
+	orgID            string
+	maxStaleness     time.Duration
+	pageSize         int
+	fullSyncInterval time.Duration
+	now              func() time.Time
+
+	mu           sync.Mutex
+	lastSync     time.Time
+	lastFullSync time.Time
+
+	// latest modified_on seen, the next Refresh lists accounts modified since
+	cursor time.Time
+
+	// set once the API returned accounts older than the modified_on filter
+	filterIgnored bool
+}
+
+func New(service form3.AccountsAPI, store Store, orgID string, options *Options) *Mirror {
+	m := &Mirror{
+		service:          service,
+		store:            store,
+		orgID:            orgID,
+		maxStaleness:     defaultMaxStaleness,
+		pageSize:         defaultPageSize,
+		fullSyncInterval: defaultFullSyncInterval,
+		now:              time.Now,
+	}
+
+	if options != nil && options.MaxStaleness > 0 {
+		m.maxStaleness = options.MaxStaleness
+	}
+	if options != nil && options.PageSize > 0 {
+		m.pageSize = options.PageSize
+	}
+	if options != nil && options.FullSyncInterval > 0 {
+		m.fullSyncInterval = options.FullSyncInterval
+	}
+
+	return m
+}
+
+// Time of the last completed sync, zero before the first one.
+func (m *Mirror) LastSync() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastSync
+}
+
+// Replaces the store content with all accounts of the organisation.
+func (m *Mirror) FullSync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sync(ctx, true)
+}
+
+// Writes accounts modified since the previous sync. Deleted accounts are removed
+// when all accounts are listed again, once FullSyncInterval passed or while no
+// modified_on has been seen yet, and on every Refresh when the API ignores the
+// modified_on filter.
+func (m *Mirror) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.refresh(ctx)
+}
+
+func (m *Mirror) refresh(ctx context.Context) error {
+	if m.filterIgnored || m.cursor.IsZero() || m.now().Sub(m.lastFullSync) >= m.fullSyncInterval {
+		return m.sync(ctx, false)
+	}
+
+	started := m.now()
+
+	// modified_on at the cursor is listed again, so accounts modified within
+	// the same instant after the previous sync are not missed
+	modified, err := m.listAll(ctx, m.cursor)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range modified {
+		if account.ModifiedOn == nil || account.ModifiedOn.Before(m.cursor) {
+			// the filter was ignored, so this is a listing of all accounts
+			m.filterIgnored = true
+			return m.reconcile(ctx, modified, false, started)
+		}
+	}
+
+	var changed []*form3.Account
+	for _, account := range modified {
+		previous, err := m.store.Get(account.ID)
+		if err != nil {
+			return err
+		}
+		if previous == nil || isChanged(previous, account) {
+			changed = append(changed, account)
+		}
+	}
+	if err := m.store.Put(changed...); err != nil {
+		return err
+	}
+
+	m.advanceCursor(modified)
+	m.lastSync = started
+	return nil
+}
+
+// Lists all accounts, writes changed ones and removes deleted ones.
+func (m *Mirror) sync(ctx context.Context, full bool) error {
+	started := m.now()
+
+	current, err := m.listAll(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	return m.reconcile(ctx, current, full, started)
+}
+
+// Makes the store match the listing of all accounts.
+func (m *Mirror) reconcile(ctx context.Context, current map[string]*form3.Account, full bool, started time.Time) error {
+	stored, err := m.store.All()
+	if err != nil {
+		return err
+	}
+
+	storedByID := make(map[string]*form3.Account, len(stored))
+	for _, account := range stored {
+		storedByID[account.ID] = account
+	}
+
+	var changed []*form3.Account
+	for id, account := range current {
+		if previous, ok := storedByID[id]; full || !ok || isChanged(previous, account) {
+			changed = append(changed, account)
+		}
+	}
+	if err := m.store.Put(changed...); err != nil {
+		return err
+	}
+
+	var deleted []string
+	for id := range storedByID {
+		if _, ok := current[id]; ok {
+			continue
+		}
+
+		// accounts deleted while paging shift others to the previous page,
+		// so a missing account is confirmed to be gone before it is removed
+		account, _, err := m.service.ByID(ctx, id)
+		if form3.IsNotFound(err) || (err == nil && account.OrganisationID != m.orgID) {
+			deleted = append(deleted, id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if isChanged(storedByID[id], account) {
+			if err := m.store.Put(account); err != nil {
+				return err
+			}
+		}
+	}
+	if err := m.store.Delete(deleted...); err != nil {
+		return err
+	}
+
+	m.advanceCursor(current)
+	m.lastSync = started
+	m.lastFullSync = started
+	return nil
+}
+
+// Moves the cursor to the latest modified_on of the accounts.
+func (m *Mirror) advanceCursor(accounts map[string]*form3.Account) {
+	for _, account := range accounts {
+		if account.ModifiedOn != nil && account.ModifiedOn.After(m.cursor) {
+			m.cursor = *account.ModifiedOn
+		}
+	}
+}
+
+// Accounts of the organisation by ID, only those modified from the time unless it is zero.
+func (m *Mirror) listAll(ctx context.Context, modifiedFrom time.Time) (map[string]*form3.Account, error) {
+	current := make(map[string]*form3.Account)
+
+	for page := 0; ; page++ {
+		options := &form3.ListOptions{
+			Number: strconv.Itoa(page),
+			Size:   m.pageSize,
+			Filter: map[string]string{"organisation_id": m.orgID},
+		}
+		if !modifiedFrom.IsZero() {
+			options.Filter[modifiedFromFilter] = modifiedFrom.Format(time.RFC3339Nano)
+		}
+
+		accounts, _, err := m.service.List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range accounts {
+			current[account.ID] = account
+		}
+
+		if len(accounts) < m.pageSize {
+			return current, nil
+		}
+	}
+}
+
+func isChanged(previous, current *form3.Account) bool {
+	if previous.Version != current.Version {
+		return true
+	}
+
+	if previous.ModifiedOn == nil || current.ModifiedOn == nil {
+		return previous.ModifiedOn != current.ModifiedOn
+	}
+
+	return !previous.ModifiedOn.Equal(*current.ModifiedOn)
+}
+
+// Syncs the store when the last sync is older than the staleness bound.
+func (m *Mirror) ensureFresh(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.lastSync.IsZero() && m.now().Sub(m.lastSync) <= m.maxStaleness {
+		return nil
+	}
+
+	if m.lastSync.IsZero() {
+		return m.sync(ctx, true)
+	}
+
+	return m.refresh(ctx)
+}
+
+// Account by ID, nil when the organisation has no such account.
+func (m *Mirror) ByID(ctx context.Context, id string) (*form3.Account, error) {
+	if err := m.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.store.Get(id)
+}
+
+func (m *Mirror) ByIBAN(ctx context.Context, iban string) ([]*form3.Account, error) {
+	if err := m.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.store.Find(IndexIBAN, iban)
+}
+
+func (m *Mirror) ByCustomerID(ctx context.Context, customerID string) ([]*form3.Account, error) {
+	if err := m.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.store.Find(IndexCustomerID, customerID)
+}
+
+func (m *Mirror) All(ctx context.Context) ([]*form3.Account, error) {
+	if err := m.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.store.All()
+}
@@ -0,0 +1,260 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"github.com/ig-hit/form3/form3mock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const orgID = "org"
+
+// Accounts served by the test server, counting list requests.
+type server struct {
+	mu       sync.Mutex
+	accounts []*form3.Account
+	lists    int
+
+	// serves all accounts whatever the modified_on filter
+	ignoreModifiedFrom bool
+}
+
+func (s *server) set(accounts ...*form3.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts = accounts
+}
+
+func (s *server) listCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lists
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/organisation/accounts" {
+		s.lists++
+		query := r.URL.Query()
+		number, _ := strconv.Atoi(query.Get("page[number]"))
+		size, _ := strconv.Atoi(query.Get("page[size]"))
+
+		modifiedFrom, _ := time.Parse(time.RFC3339Nano, query.Get("filter[modified_on_from]"))
+		if s.ignoreModifiedFrom {
+			modifiedFrom = time.Time{}
+		}
+
+		var matching []*form3.Account
+		for _, a := range s.accounts {
+			if a.OrganisationID != query.Get("filter[organisation_id]") {
+				continue
+			}
+			if !modifiedFrom.IsZero() && (a.ModifiedOn == nil || a.ModifiedOn.Before(modifiedFrom)) {
+				continue
+			}
+			matching = append(matching, a)
+		}
+
+		page := []*form3.Account{}
+		for i := number * size; i < (number+1)*size && i < len(matching); i++ {
+			page = append(page, matching[i])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": page})
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/organisation/accounts/")
+	for _, a := range s.accounts {
+		if a.ID == id {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": a})
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = fmt.Fprintf(w, `{"error_message":"record %s does not exist"}`, id)
+}
+
+func setup(store Store) (m *Mirror, api *server, clock *time.Time, teardown func()) {
+	api = &server{}
+	httpServer := httptest.NewServer(api)
+	service := form3.CreateAccountsServiceWithOptions(&form3.ClientOptions{Timeout: 3000, BaseEndpoint: httpServer.URL})
+
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	clock = &now
+	m = New(service, store, orgID, &Options{MaxStaleness: time.Minute, PageSize: 2})
+	m.now = func() time.Time { return *clock }
+
+	return m, api, clock, httpServer.Close
+}
+
+func versioned(account *form3.Account, version int) *form3.Account {
+	account.Version = version
+	return account
+}
+
+func TestMirror_QueriesSyncOnFirstUse(t *testing.T) {
+	m, api, _, teardown := setup(NewMemoryStore())
+	defer teardown()
+
+	other := form3.MakeAccount("4", "other-org")
+	api.set(makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-1"), makeAccount("3", "GB3", "c-2"), other)
+
+	found, err := m.ByCustomerID(context.Background(), "c-1")
+	assert.Nil(t, err)
+	assert.Equal(t, []*form3.Account{makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-1")}, found)
+
+	found, err = m.ByIBAN(context.Background(), "GB3")
+	assert.Nil(t, err)
+	assert.Equal(t, "3", found[0].ID)
+
+	account, err := m.ByID(context.Background(), "4")
+	assert.Nil(t, err)
+	assert.Nil(t, account)
+
+	// second page is requested as the first one is full
+	assert.Equal(t, 2, api.listCount())
+}
+
+func TestMirror_StalenessBound(t *testing.T) {
+	m, api, clock, teardown := setup(NewMemoryStore())
+	defer teardown()
+
+	api.set(makeAccount("1", "GB1", "c-1"))
+	_, _ = m.All(context.Background())
+	lists := api.listCount()
+
+	api.set(makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-2"))
+	*clock = clock.Add(time.Minute)
+	all, _ := m.All(context.Background())
+	assert.Equal(t, 1, len(all), "data within staleness bound is served locally")
+	assert.Equal(t, lists, api.listCount())
+
+	*clock = clock.Add(time.Second)
+	all, _ = m.All(context.Background())
+	assert.Equal(t, 2, len(all))
+	assert.Equal(t, *clock, m.LastSync())
+}
+
+// Store recording written accounts.
+type recordingStore struct {
+	Store
+	put []string
+}
+
+func (s *recordingStore) Put(accounts ...*form3.Account) error {
+	for _, a := range accounts {
+		s.put = append(s.put, a.ID)
+	}
+	return s.Store.Put(accounts...)
+}
+
+func TestMirror_RefreshWritesOnlyChanges(t *testing.T) {
+	store := &recordingStore{Store: NewMemoryStore()}
+	m, api, _, teardown := setup(store)
+	defer teardown()
+
+	api.set(makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-1"), makeAccount("3", "GB3", "c-1"))
+	assert.Nil(t, m.FullSync(context.Background()))
+	assert.Equal(t, 3, len(store.put))
+
+	store.put = nil
+	api.set(makeAccount("1", "GB1", "c-1"), versioned(makeAccount("3", "GB4", "c-1"), 1))
+	assert.Nil(t, m.Refresh(context.Background()))
+	assert.Equal(t, []string{"3"}, store.put)
+
+	all, _ := store.All()
+	assert.Equal(t, []*form3.Account{makeAccount("1", "GB1", "c-1"), versioned(makeAccount("3", "GB4", "c-1"), 1)}, all)
+}
+
+func modified(account *form3.Account, version int, on time.Time) *form3.Account {
+	account.Version = version
+	account.ModifiedOn = &on
+	return account
+}
+
+func TestMirror_RefreshListsModifiedSinceCursor(t *testing.T) {
+	store := &recordingStore{Store: NewMemoryStore()}
+	m, api, clock, teardown := setup(store)
+	defer teardown()
+	m.fullSyncInterval = time.Hour
+
+	t0 := time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)
+	api.set(modified(makeAccount("1", "GB1", "c-1"), 0, t0), modified(makeAccount("2", "GB2", "c-1"), 0, t0), modified(makeAccount("3", "GB3", "c-1"), 0, t0))
+	assert.Nil(t, m.FullSync(context.Background()))
+	lists := api.listCount()
+
+	// only the modified account is listed, the deleted one is kept until the next full sync
+	store.put = nil
+	api.set(modified(makeAccount("1", "GB1", "c-1"), 0, t0), modified(makeAccount("2", "GB9", "c-1"), 1, t0.Add(time.Minute)))
+	assert.Nil(t, m.Refresh(context.Background()))
+	// a full page of two, account 1 is modified at the cursor and listed again
+	assert.Equal(t, lists+2, api.listCount())
+	assert.Equal(t, []string{"2"}, store.put)
+	all, _ := store.All()
+	assert.Len(t, all, 3)
+
+	*clock = clock.Add(time.Hour)
+	assert.Nil(t, m.Refresh(context.Background()))
+	all, _ = store.All()
+	assert.Equal(t, []*form3.Account{
+		modified(makeAccount("1", "GB1", "c-1"), 0, t0),
+		modified(makeAccount("2", "GB9", "c-1"), 1, t0.Add(time.Minute)),
+	}, all)
+}
+
+func TestMirror_RefreshDetectsIgnoredFilter(t *testing.T) {
+	m, api, _, teardown := setup(NewMemoryStore())
+	defer teardown()
+	api.ignoreModifiedFrom = true
+
+	t0 := time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)
+	api.set(modified(makeAccount("1", "GB1", "c-1"), 0, t0), modified(makeAccount("2", "GB2", "c-1"), 0, t0.Add(time.Minute)))
+	assert.Nil(t, m.FullSync(context.Background()))
+
+	// the listing is complete, so the deleted account is removed right away
+	api.set(modified(makeAccount("1", "GB1", "c-1"), 0, t0))
+	assert.Nil(t, m.Refresh(context.Background()))
+	assert.True(t, m.filterIgnored)
+	all, _ := m.store.All()
+	assert.Equal(t, []*form3.Account{modified(makeAccount("1", "GB1", "c-1"), 0, t0)}, all)
+}
+
+func TestMirror_SyncError(t *testing.T) {
+	m, _, _, teardown := setup(NewMemoryStore())
+	teardown()
+
+	_, err := m.All(context.Background())
+	assert.NotNil(t, err)
+	assert.True(t, m.LastSync().IsZero())
+}
+
+func TestMirror_ConfirmationErrorKeepsAccount(t *testing.T) {
+	fake := form3mock.NewAccounts(makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-1"))
+	m := New(fake, NewMemoryStore(), orgID, nil)
+	assert.Nil(t, m.FullSync(context.Background()))
+
+	_, _ = fake.Delete(context.Background(), "2", 0)
+	unavailable := form3mock.APIError(http.StatusServiceUnavailable, "")
+	fake.InjectErrorOnce(form3mock.MethodByID, unavailable)
+	assert.Equal(t, unavailable, m.Refresh(context.Background()))
+
+	all, _ := m.store.All()
+	assert.Len(t, all, 2)
+
+	fake.Expect(form3mock.MethodByID).WithID("2").Times(2)
+	assert.Nil(t, m.Refresh(context.Background()))
+	all, _ = m.store.All()
+	assert.Equal(t, []*form3.Account{makeAccount("1", "GB1", "c-1")}, all)
+	fake.AssertExpectations(t)
+}
@@ -0,0 +1,160 @@
+package mirror
+
+import (
+	"github.com/ig-hit/form3"
+	"sort"
+	"sync"
+)
+
+// Attribute accounts can be looked up by.
+type Index string
+
+const (
+	IndexIBAN       Index = "iban"
+	IndexCustomerID Index = "customer_id"
+)
+
+func indexValue(account *form3.Account, index Index) string {
+	if account.Attributes == nil {
+		return ""
+	}
+
+	switch index {
+	case IndexIBAN:
+		return account.Attributes.IBAN
+	case IndexCustomerID:
+		return account.Attributes.CustomerID
+	}
+
+	return ""
+}
+
+var indexes = []Index{IndexIBAN, IndexCustomerID}
+
+// Local storage of mirrored accounts, accounts are copied in and out
+// so callers are free to modify what they pass and get.
+type Store interface {
+	// Inserts or replaces accounts by ID.
+	Put(accounts ...*form3.Account) error
+
+	// Removes accounts, unknown IDs are ignored.
+	Delete(ids ...string) error
+
+	// Account by ID, nil when it is not stored.
+	Get(id string) (*form3.Account, error)
+
+	// Accounts having the value of the index, ordered by ID.
+	Find(index Index, value string) ([]*form3.Account, error)
+
+	// All stored accounts, ordered by ID.
+	All() ([]*form3.Account, error)
+}
+
+type memoryStore struct {
+	mu       sync.RWMutex
+	accounts map[string]*form3.Account
+	indexed  map[Index]map[string]map[string]bool
+}
+
+// Creates store keeping accounts in memory.
+func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
+	s := &memoryStore{
+		accounts: make(map[string]*form3.Account),
+		indexed:  make(map[Index]map[string]map[string]bool),
+	}
+	for _, index := range indexes {
+		s.indexed[index] = make(map[string]map[string]bool)
+	}
+
+	return s
+}
+
+func (s *memoryStore) Put(accounts ...*form3.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, account := range accounts {
+		account = account.Clone()
+		s.remove(account.ID)
+		s.accounts[account.ID] = account
+
+		for _, index := range indexes {
+			value := indexValue(account, index)
+			if value == "" {
+				continue
+			}
+			if s.indexed[index][value] == nil {
+				s.indexed[index][value] = make(map[string]bool)
+			}
+			s.indexed[index][value][account.ID] = true
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Delete(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		s.remove(id)
+	}
+
+	return nil
+}
+
+func (s *memoryStore) remove(id string) {
+	account, ok := s.accounts[id]
+	if !ok {
+		return
+	}
+
+	for _, index := range indexes {
+		value := indexValue(account, index)
+		delete(s.indexed[index][value], id)
+		if len(s.indexed[index][value]) == 0 {
+			delete(s.indexed[index], value)
+		}
+	}
+	delete(s.accounts, id)
+}
+
+func (s *memoryStore) Get(id string) (*form3.Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.accounts[id].Clone(), nil
+}
+
+func (s *memoryStore) Find(index Index, value string) ([]*form3.Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []*form3.Account
+	for id := range s.indexed[index][value] {
+		found = append(found, s.accounts[id].Clone())
+	}
+
+	return sortByID(found), nil
+}
+
+func (s *memoryStore) All() ([]*form3.Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make([]*form3.Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		all = append(all, account.Clone())
+	}
+
+	return sortByID(all), nil
+}
+
+func sortByID(accounts []*form3.Account) []*form3.Account {
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+	return accounts
+}
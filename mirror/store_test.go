@@ -0,0 +1,72 @@
+package mirror
+
+import (
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func makeAccount(id, iban, customerID string) *form3.Account {
+	account := form3.MakeAccount(id, orgID)
+	account.Attributes = &form3.AccountAttributes{IBAN: iban, CustomerID: customerID}
+	return account
+}
+
+func testStore(t *testing.T, store Store) {
+	assert.Nil(t, store.Put(makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-1"), makeAccount("3", "", "c-2")))
+
+	found, err := store.Find(IndexCustomerID, "c-1")
+	assert.Nil(t, err)
+	assert.Equal(t, []*form3.Account{makeAccount("1", "GB1", "c-1"), makeAccount("2", "GB2", "c-1")}, found)
+
+	// replacing an account updates indexes
+	assert.Nil(t, store.Put(makeAccount("2", "GB3", "c-2")))
+	found, _ = store.Find(IndexIBAN, "GB2")
+	assert.Empty(t, found)
+	found, _ = store.Find(IndexIBAN, "GB3")
+	assert.Equal(t, []*form3.Account{makeAccount("2", "GB3", "c-2")}, found)
+
+	assert.Nil(t, store.Delete("1", "unknown"))
+	account, err := store.Get("1")
+	assert.Nil(t, err)
+	assert.Nil(t, account)
+
+	all, err := store.All()
+	assert.Nil(t, err)
+	assert.Equal(t, []*form3.Account{makeAccount("2", "GB3", "c-2"), makeAccount("3", "", "c-2")}, all)
+
+	// modifying accounts put or returned leaves the store and its indexes intact
+	put := makeAccount("4", "GB4", "c-4")
+	assert.Nil(t, store.Put(put))
+	put.Attributes.IBAN = "changed"
+	got, _ := store.Get("4")
+	got.Attributes.CustomerID = "changed"
+	all[0].Attributes.IBAN = "changed"
+	found, _ = store.Find(IndexIBAN, "GB4")
+	assert.Equal(t, []*form3.Account{makeAccount("4", "GB4", "c-4")}, found)
+	found, _ = store.Find(IndexIBAN, "GB3")
+	assert.Equal(t, []*form3.Account{makeAccount("2", "GB3", "c-2")}, found)
+	assert.Nil(t, store.Delete("4"))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "mirror")
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	assert.Nil(t, err)
+	testStore(t, store)
+
+	reopened, err := NewFileStore(dir)
+	assert.Nil(t, err)
+	all, _ := reopened.All()
+	assert.Equal(t, []*form3.Account{makeAccount("2", "GB3", "c-2"), makeAccount("3", "", "c-2")}, all)
+	found, _ := reopened.Find(IndexCustomerID, "c-2")
+	assert.Equal(t, 2, len(found))
+}
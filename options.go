@@ -0,0 +1,262 @@
+package form3
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Configures a client created by NewClient.
+type Option func(options *ClientOptions) error
+
+// Creates client from the default options changed by opts.
+func NewClient(opts ...Option) (*Client, error) {
+	options := *defaultClientOptions
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	return buildClient(options)
+}
+
+// Builds client from the options as they are, only the base URL and the transport can fail.
+func buildClient(options ClientOptions) (*Client, error) {
+	baseURL, err := url.Parse(options.BaseEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %v", options.BaseEndpoint, err)
+	}
+
+	if options.Transport == nil {
+		transport, err := transportFor(options.TransportOptions)
+		if err != nil {
+			return nil, err
+		}
+		options.Transport = transport
+	}
+
+	timeout := options.DefaultTimeout
+	if timeout == 0 {
+		timeout = time.Duration(options.Timeout) * time.Millisecond
+	}
+
+	return &Client{
+		httpClient:            provideHTTPClient(&options),
+		timeout:               timeout,
+		operationTimeouts:     options.OperationTimeouts,
+		accountsCache:         newAccountCache(options.AccountsCache),
+		hooks:                 options.Hooks,
+		userAgent:             options.UserAgent,
+		defaultOrganisationID: options.DefaultOrganisationID,
+		production:            options.Production,
+		allowDestructive:      options.AllowDestructive,
+		strictEnums:           options.StrictEnums,
+		BaseURL:               baseURL,
+	}, nil
+}
+
+func (o *ClientOptions) validate() error {
+	baseURL, err := url.Parse(o.BaseEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %v", o.BaseEndpoint, err)
+	}
+	if (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return fmt.Errorf("invalid base URL %q: absolute http or https URL expected", o.BaseEndpoint)
+	}
+
+	if o.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %dms: must not be negative", o.Timeout)
+	}
+	if o.DefaultTimeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must not be negative", o.DefaultTimeout)
+	}
+	for operation, timeout := range o.OperationTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout %s of %s: must not be negative", timeout, operation)
+		}
+	}
+
+	if o.Retry != nil && (o.Retry.MaxAttempts < 0 || o.Retry.InitialBackoff < 0 || o.Retry.MaxBackoff < 0) {
+		return fmt.Errorf("invalid retry policy: values must not be negative")
+	}
+
+	limits := map[string]*RateLimit{"client": o.RateLimit}
+	for endpoint, limit := range o.EndpointRateLimits {
+		limits[endpoint] = limit
+	}
+	for name, limit := range limits {
+		if limit != nil && (limit.Rate < 0 || limit.Burst < 0 || limit.MaxInFlight < 0) {
+			return fmt.Errorf("invalid rate limit of %s: values must not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// Uses all options, empty BaseEndpoint keeps the default one.
+func WithClientOptions(options *ClientOptions) Option {
+	return func(o *ClientOptions) error {
+		baseEndpoint := o.BaseEndpoint
+		*o = *options
+		if o.BaseEndpoint == "" {
+			o.BaseEndpoint = baseEndpoint
+		}
+		return nil
+	}
+}
+
+// Base URL of the API, e.g. http://localhost:8080/v1
+func WithBaseURL(baseURL string) Option {
+	return func(o *ClientOptions) error {
+		o.BaseEndpoint = baseURL
+		return nil
+	}
+}
+
+// Timeout of operations without one of their own in OperationTimeouts or
+// DefaultOperationTimeouts, the package DefaultTimeout applies again when 0.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *ClientOptions) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout %s: must not be negative", timeout)
+		}
+		o.DefaultTimeout = timeout
+		o.Timeout = 0
+		return nil
+	}
+}
+
+// Timeout of the operation, e.g. accounts.List, or of a method of every service, e.g. List.
+// No timeout when 0.
+func WithOperationTimeout(operation string, timeout time.Duration) Option {
+	return func(o *ClientOptions) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout %s of %s: must not be negative", timeout, operation)
+		}
+		timeouts := make(map[string]time.Duration, len(o.OperationTimeouts)+1)
+		for k, v := range o.OperationTimeouts {
+			timeouts[k] = v
+		}
+		timeouts[operation] = timeout
+		o.OperationTimeouts = timeouts
+		return nil
+	}
+}
+
+// User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *ClientOptions) error {
+		o.UserAgent = userAgent
+		return nil
+	}
+}
+
+// Transport used to send requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *ClientOptions) error {
+		if transport == nil {
+			return fmt.Errorf("transport must not be nil")
+		}
+		o.Transport = transport
+		return nil
+	}
+}
+
+// Tunes the connection pool, proxy and TLS of the default transport.
+func WithTransportOptions(options *TransportOptions) Option {
+	return func(o *ClientOptions) error {
+		o.TransportOptions = options
+		return nil
+	}
+}
+
+func WithRetry(policy *RetryPolicy) Option {
+	return func(o *ClientOptions) error {
+		o.Retry = policy
+		return nil
+	}
+}
+
+// Logs requests at info level and above.
+func WithLogger(logger Logger) Option {
+	return func(o *ClientOptions) error {
+		if logger == nil {
+			return fmt.Errorf("logger must not be nil")
+		}
+		if o.Logging == nil {
+			o.Logging = &LoggingOptions{}
+		}
+		o.Logging.Logger = logger
+		return nil
+	}
+}
+
+func WithLogging(options *LoggingOptions) Option {
+	return func(o *ClientOptions) error {
+		o.Logging = options
+		return nil
+	}
+}
+
+// Organisation stamped on created resources that have none.
+func WithDefaultOrganisationID(orgID string) Option {
+	return func(o *ClientOptions) error {
+		o.DefaultOrganisationID = orgID
+		return nil
+	}
+}
+
+// Fails requests and responses with unknown codes, see ValidateCodes.
+func WithStrictEnums(strict bool) Option {
+	return func(o *ClientOptions) error {
+		o.StrictEnums = strict
+		return nil
+	}
+}
+
+func WithAccountsCache(options *CacheOptions) Option {
+	return func(o *ClientOptions) error {
+		o.AccountsCache = options
+		return nil
+	}
+}
+
+func WithRateLimit(limit *RateLimit) Option {
+	return func(o *ClientOptions) error {
+		o.RateLimit = limit
+		return nil
+	}
+}
+
+// Limits requests of the endpoint, e.g. "POST /organisation/accounts".
+func WithEndpointRateLimit(endpoint string, limit *RateLimit) Option {
+	return func(o *ClientOptions) error {
+		limits := make(map[string]*RateLimit, len(o.EndpointRateLimits)+1)
+		for k, v := range o.EndpointRateLimits {
+			limits[k] = v
+		}
+		limits[endpoint] = limit
+		o.EndpointRateLimits = limits
+		return nil
+	}
+}
+
+func WithCircuitBreaker(options *CircuitBreakerOptions) Option {
+	return func(o *ClientOptions) error {
+		o.CircuitBreaker = options
+		return nil
+	}
+}
+
+func WithTelemetry(options *TelemetryOptions) Option {
+	return func(o *ClientOptions) error {
+		o.Telemetry = options
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	var userAgent string
+	var created Account
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		_ = json.NewDecoder(r.Body).Decode(&body{Data: &created})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data": {"id": "1"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		WithBaseURL(server.URL+"/v1"),
+		WithTimeout(2*time.Second),
+		WithUserAgent("form3-test/1.0"),
+		WithDefaultOrganisationID("org"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Second, client.operationTimeout("accounts.ByID"))
+	assert.Equal(t, 15*time.Second, client.operationTimeout("accounts.List"))
+	assert.Equal(t, "org", client.DefaultOrganisationID())
+
+	account := MakeAccount("1", "")
+	_, _, err = CreateAccountsService(client).Create(context.Background(), account)
+	assert.Nil(t, err)
+	assert.Equal(t, "form3-test/1.0", userAgent)
+	assert.Equal(t, "org", created.OrganisationID)
+	assert.Equal(t, "", account.OrganisationID)
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	client, err := NewClient()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/v1", client.BaseURL.String())
+	assert.Equal(t, time.Duration(0), client.httpClient.Timeout)
+	assert.Equal(t, 5*time.Second, client.operationTimeout("accounts.Create"))
+	assert.Equal(t, 15*time.Second, client.operationTimeout("accounts.List"))
+	assert.Equal(t, 3*time.Second, client.operationTimeout("accounts.Delete"))
+	assert.Equal(t, 3*time.Second, client.operationTimeout("accounts.ByID"))
+}
+
+func TestNewClient_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		option Option
+		err    string
+	}{
+		"relative url":  {WithBaseURL("localhost:8080/v1"), `invalid base URL "localhost:8080/v1": absolute http or https URL expected`},
+		"ftp url":       {WithBaseURL("ftp://localhost/v1"), `invalid base URL "ftp://localhost/v1": absolute http or https URL expected`},
+		"negative":      {WithTimeout(-time.Second), "invalid timeout -1s: must not be negative"},
+		"nil transport": {WithTransport(nil), "transport must not be nil"},
+		"nil logger":    {WithLogger(nil), "logger must not be nil"},
+		"retry":         {WithRetry(&RetryPolicy{MaxAttempts: 3, MaxBackoff: -1}), "invalid retry policy: values must not be negative"},
+		"rate limit":    {WithEndpointRateLimit("GET /x", &RateLimit{Rate: -1}), "invalid rate limit of GET /x: values must not be negative"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewClient(tt.option)
+			assert.Nil(t, client)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewClient_UnparsableURL(t *testing.T) {
+	_, err := NewClient(WithBaseURL("http://[::1"))
+	assert.Contains(t, err.Error(), `invalid base URL "http://[::1": `)
+}
+
+func TestCreateClient_Invalid(t *testing.T) {
+	client := CreateClient(&ClientOptions{BaseEndpoint: "localhost"})
+	assert.Equal(t, "localhost", client.BaseURL.String())
+
+	_, err := NewClientWithOptions(&ClientOptions{BaseEndpoint: "localhost"})
+	assert.EqualError(t, err, `invalid base URL "localhost": absolute http or https URL expected`)
+
+	_, err = NewAccountsServiceWithOptions(&ClientOptions{BaseEndpoint: "localhost"})
+	assert.NotNil(t, err)
+}
+
+func TestCreateClient_TransportError(t *testing.T) {
+	client := CreateClient(&ClientOptions{TransportOptions: &TransportOptions{CAFile: "missing.pem"}})
+
+	_, _, err := CreateAccountsService(client).ByID(context.Background(), "1")
+	assert.Contains(t, err.Error(), "missing.pem")
+
+	_, err = NewClientWithOptions(&ClientOptions{TransportOptions: &TransportOptions{CAFile: "missing.pem"}})
+	assert.Contains(t, err.Error(), "missing.pem")
+}
+
+func TestCreateClient_InvalidBaseURL(t *testing.T) {
+	client := CreateClient(&ClientOptions{BaseEndpoint: "http://[::1"})
+	assert.NotNil(t, client.BaseURL)
+
+	_, _, err := CreateAccountsService(client).ByID(context.Background(), "1")
+	assert.Contains(t, err.Error(), `invalid base URL "http://[::1"`)
+}
+
+func TestCreateClient_PartialOptions(t *testing.T) {
+	client := CreateClient(&ClientOptions{UserAgent: "agent"})
+	assert.Equal(t, "http://localhost:8080/v1", client.BaseURL.String())
+	assert.Equal(t, "agent", client.userAgent)
+}
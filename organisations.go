@@ -0,0 +1,209 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	organisationsBaseEndpoint = "/organisation/units"
+)
+
+type OrganisationsService service
+
+type Organisation struct {
+	ID             string                     `json:"id"`
+	OrganisationID string                     `json:"organisation_id,omitempty"`
+	Type           string                     `json:"type"`
+	Attributes     *OrganisationAttributes    `json:"attributes,omitempty"`
+	Version        int                        `json:"version"`
+	Relationships  *OrganisationRelationships `json:"relationships,omitempty"`
+	CreatedOn      *time.Time                 `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time                 `json:"modified_on,omitempty"`
+}
+
+type OrganisationAttributes struct {
+	Name string `json:"name"`
+}
+
+type OrganisationRelationships struct {
+	// Organisation this unit belongs to
+	Parent *Relationship `json:"parent,omitempty"`
+}
+
+// Build new organisation.
+func MakeOrganisation(id, name string) *Organisation {
+	return &Organisation{
+		ID:         id,
+		Type:       "organisations",
+		Attributes: &OrganisationAttributes{Name: name},
+	}
+}
+
+// Build new organisation unit belonging to the parent organisation.
+func MakeOrganisationUnit(id, parentID, name string) *Organisation {
+	unit := MakeOrganisation(id, name)
+	unit.OrganisationID = parentID
+	unit.Relationships = &OrganisationRelationships{
+		Parent: makeRelationship(parentID, "organisations"),
+	}
+
+	return unit
+}
+
+// ID of the parent organisation, empty for top level organisations.
+func (o *Organisation) ParentID() string {
+	if o.Relationships == nil {
+		return ""
+	}
+
+	return o.Relationships.Parent.ID()
+}
+
+// Creates new organisation.
+func (s *OrganisationsService) Create(ctx context.Context, data *Organisation, opts ...CallOption) (*Organisation, *Response, error) {
+	if data == nil {
+		return nil, nil, ErrNilData
+	}
+
+	ctx = withOperation(ctx, "organisations.Create", opts...)
+	ctx = withResourceID(ctx, data.ID)
+	client := s.client
+
+	req, err := client.POST(organisationsBaseEndpoint, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	organisation := new(Organisation)
+	resp, err := client.Do(ctx, req, organisation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return organisation, resp, nil
+}
+
+// Retrieves organisation by ID.
+func (s *OrganisationsService) ByID(ctx context.Context, id string, opts ...CallOption) (*Organisation, *Response, error) {
+	ctx = withOperation(ctx, "organisations.ByID", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+	url := fmt.Sprintf("%s/%s", organisationsBaseEndpoint, id)
+
+	req, err := client.GET(url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	organisation := new(Organisation)
+	resp, err := client.Do(ctx, req, organisation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return organisation, resp, nil
+}
+
+// Get list of organisations.
+func (s *OrganisationsService) List(ctx context.Context, options *ListOptions, opts ...CallOption) ([]*Organisation, *Response, error) {
+	ctx = withOperation(ctx, "organisations.List", opts...)
+	return s.list(ctx, addListOptions(organisationsBaseEndpoint, options))
+}
+
+// Get list of units belonging to the parent organisation.
+func (s *OrganisationsService) ListUnits(ctx context.Context, parentID string, options *ListOptions, opts ...CallOption) ([]*Organisation, *Response, error) {
+	ctx = withOperation(ctx, "organisations.ListUnits", opts...)
+	unitOptions := &ListOptions{Filter: map[string]string{"parent_id": parentID}}
+	if options != nil {
+		unitOptions.Number = options.Number
+		unitOptions.Size = options.Size
+		for k, v := range options.Filter {
+			unitOptions.Filter[k] = v
+		}
+	}
+
+	return s.list(ctx, addListOptions(organisationsBaseEndpoint, unitOptions))
+}
+
+func (s *OrganisationsService) list(ctx context.Context, reqUrl string) ([]*Organisation, *Response, error) {
+	client := s.client
+
+	req, err := client.GET(reqUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	organisations := new([]*Organisation)
+	resp, err := client.Do(ctx, req, organisations)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *organisations, resp, nil
+}
+
+// Updates organisation, data must carry the current version.
+func (s *OrganisationsService) Update(ctx context.Context, data *Organisation, opts ...CallOption) (*Organisation, *Response, error) {
+	if data == nil {
+		return nil, nil, ErrNilData
+	}
+
+	ctx = withOperation(ctx, "organisations.Update", opts...)
+	ctx = withResourceID(ctx, data.ID)
+	client := s.client
+	url := fmt.Sprintf("%s/%s", organisationsBaseEndpoint, data.ID)
+
+	req, err := client.PATCH(url, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	organisation := new(Organisation)
+	resp, err := client.Do(ctx, req, organisation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return organisation, resp, nil
+}
+
+// Delete organisation by id and version
+func (s *OrganisationsService) Delete(ctx context.Context, id string, version int, opts ...CallOption) (*Response, error) {
+	ctx = withOperation(ctx, "organisations.Delete", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+
+	if err := client.CheckDestructive("organisations.Delete"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%s?version=%d", organisationsBaseEndpoint, id, version)
+
+	req, err := client.DELETE(url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(ctx, req, nil)
+}
+
+// Retrieves organisation by ID, creating it when it does not exist yet.
+// Meant for provisioning test and sandbox environments.
+func (s *OrganisationsService) Ensure(ctx context.Context, data *Organisation, opts ...CallOption) (*Organisation, error) {
+	if data == nil {
+		return nil, ErrNilData
+	}
+
+	organisation, _, err := s.ByID(ctx, data.ID, opts...)
+	if err == nil {
+		return organisation, nil
+	}
+
+	if !IsNotFound(err) {
+		return nil, err
+	}
+
+	organisation, _, err = s.Create(ctx, data, opts...)
+	return organisation, err
+}
@@ -0,0 +1,152 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func setupOrganisations() (service *OrganisationsService, mux *http.ServeMux, serverURL string, teardown func()) {
+	client, mux, serverURL, teardown := setup()
+	service = CreateOrganisationsService(client)
+	return service, mux, serverURL, teardown
+}
+
+func TestMakeOrganisationUnit(t *testing.T) {
+	unit := MakeOrganisationUnit("1", "2", "unit")
+	assert.Equal(t, "organisations", unit.Type)
+	assert.Equal(t, "2", unit.ParentID())
+	assert.Equal(t, "", MakeOrganisation("2", "org").ParentID())
+}
+
+func TestOrganisationsService_Create(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"data":{"id":"1","type":"organisations","attributes":{"name":"org"},"version":0}}`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","type":"organisations","attributes":{"name":"org"},"version":0}}`)
+	})
+
+	saved, resp, err := service.Create(context.Background(), MakeOrganisation("1", "org"))
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, MakeOrganisation("1", "org"), saved)
+}
+
+func TestOrganisationsService_ByID(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","type":"organisations","attributes":{"name":"org"},"version":0}}`)
+	})
+
+	organisation, resp, err := service.ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "org", organisation.Attributes.Name)
+}
+
+func TestOrganisationsService_ListUnits(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		query := r.URL.Query()
+		assert.Equal(t, "2", query.Get("filter[parent_id]"))
+		assert.Equal(t, "first", query.Get("page[number]"))
+		_, _ = fmt.Fprint(w, `{"data":[{"id":"1","type":"organisations","version":0}]}`)
+	})
+
+	list, _, err := service.ListUnits(context.Background(), "2", &ListOptions{Number: "first", Size: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list))
+}
+
+func TestOrganisationsService_Update(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		testBody(t, r, `{"data":{"id":"1","type":"organisations","attributes":{"name":"renamed"},"version":3}}`)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","type":"organisations","attributes":{"name":"renamed"},"version":4}}`)
+	})
+
+	organisation := MakeOrganisation("1", "renamed")
+	organisation.Version = 3
+
+	updated, _, err := service.Update(context.Background(), organisation)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, updated.Version)
+}
+
+func TestOrganisationsService_Delete(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		assert.Equal(t, "2", r.URL.Query().Get("version"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := service.Delete(context.Background(), "1", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+}
+
+func TestOrganisationsService_EnsureExisting(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","type":"organisations","attributes":{"name":"org"},"version":2}}`)
+	})
+	mux.HandleFunc("/organisation/units", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("organisation should not be created")
+	})
+
+	organisation, err := service.Ensure(context.Background(), MakeOrganisation("1", "org"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, organisation.Version)
+}
+
+func TestOrganisationsService_EnsureMissing(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"error_message":"record 1 does not exist"}`)
+	})
+	mux.HandleFunc("/organisation/units", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","type":"organisations","attributes":{"name":"org"},"version":0}}`)
+	})
+
+	organisation, err := service.Ensure(context.Background(), MakeOrganisation("1", "org"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", organisation.ID)
+}
+
+func TestOrganisationsService_EnsureError(t *testing.T) {
+	service, mux, _, teardown := setupOrganisations()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/units/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	organisation, err := service.Ensure(context.Background(), MakeOrganisation("1", "org"))
+	assert.Nil(t, organisation)
+	assert.EqualError(t, err, "500 Internal Server Error")
+}
@@ -0,0 +1,224 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+)
+
+// Returned by destructive operations of a client for production unless they are allowed.
+var ErrDestructiveOperation = errors.New("destructive operation refused against production")
+
+// How requests are authenticated.
+type AuthMethod string
+
+const (
+	AuthNone   AuthMethod = ""
+	AuthBearer AuthMethod = "bearer"
+	AuthBasic  AuthMethod = "basic"
+)
+
+// Credentials of requests, secrets can be read from environment variables
+// so they are kept out of profile files.
+type Auth struct {
+	Method AuthMethod `yaml:"method"`
+
+	// Token of bearer auth, or the variable named by TokenEnv
+	Token    string `yaml:"token"`
+	TokenEnv string `yaml:"token_env"`
+
+	// Credentials of basic auth, the password can be read from PasswordEnv
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	PasswordEnv string `yaml:"password_env"`
+}
+
+func (a *Auth) resolve(getenv func(string) string) (*Auth, error) {
+	resolved := *a
+	if resolved.Token == "" && resolved.TokenEnv != "" {
+		resolved.Token = getenv(resolved.TokenEnv)
+	}
+	if resolved.Password == "" && resolved.PasswordEnv != "" {
+		resolved.Password = getenv(resolved.PasswordEnv)
+	}
+
+	switch resolved.Method {
+	case AuthNone:
+	case AuthBearer:
+		if resolved.Token == "" {
+			return nil, errors.New("bearer auth requires a token")
+		}
+	case AuthBasic:
+		if resolved.Username == "" {
+			return nil, errors.New("basic auth requires a username")
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth method %q", resolved.Method)
+	}
+
+	return &resolved, nil
+}
+
+// Adds the Authorization header to requests.
+type authTransport struct {
+	next http.RoundTripper
+	auth *Auth
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	switch t.auth.Method {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+t.auth.Token)
+	case AuthBasic:
+		req.SetBasicAuth(t.auth.Username, t.auth.Password)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// Settings of an environment the client talks to, e.g. the local fake, staging or production.
+type Profile struct {
+	Name      string        `yaml:"-"`
+	BaseURL   string        `yaml:"base_url"`
+	Auth      *Auth         `yaml:"auth"`
+	Timeout   time.Duration `yaml:"timeout"`
+	RateLimit *RateLimit    `yaml:"rate_limit"`
+
+	// Destructive operations are refused unless AllowDestructive is set
+	Production       bool `yaml:"production"`
+	AllowDestructive bool `yaml:"allow_destructive"`
+}
+
+// Named profiles, selected by name at runtime.
+type Profiles struct {
+	Default  string              `yaml:"default_profile"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// Environment variable conventionally naming the selected profile.
+const EnvProfile = "FORM3_PROFILE"
+
+// Profile of the local fake, used when no profile is configured.
+var LocalProfile = &Profile{Name: "local", BaseURL: "http://localhost:8080/v1"}
+
+// Reads profiles from a YAML or JSON file, e.g.
+//
+//	default_profile: local
+//	profiles:
+//	  local:
+//	    base_url: http://localhost:8080/v1
+//	  production:
+//	    base_url: https://api.form3.tech/v1
+//	    production: true
+//	    auth: {method: bearer, token_env: FORM3_PRODUCTION_TOKEN}
+func LoadProfiles(path string) (*Profiles, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Profiles{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("profiles %s: %v", path, err)
+	}
+
+	return p, nil
+}
+
+// Names of the profiles in order.
+func (p *Profiles) Names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Profile by name, the default profile when empty,
+// LocalProfile when there are no profiles at all.
+func (p *Profiles) Select(name string) (*Profile, error) {
+	if name == "" {
+		name = p.Default
+	}
+	if name == "" && len(p.Profiles) == 0 {
+		return LocalProfile, nil
+	}
+
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, configured: %v", name, p.Names())
+	}
+
+	selected := *profile
+	selected.Name = name
+
+	return &selected, nil
+}
+
+// Applies the profile, its secrets are read from environment variables now.
+func WithProfile(profile *Profile) Option {
+	return func(o *ClientOptions) error {
+		if profile.BaseURL != "" {
+			o.BaseEndpoint = profile.BaseURL
+		}
+		if profile.Timeout != 0 {
+			if err := WithTimeout(profile.Timeout)(o); err != nil {
+				return err
+			}
+		}
+		if profile.RateLimit != nil {
+			o.RateLimit = profile.RateLimit
+		}
+		if profile.Auth != nil {
+			auth, err := profile.Auth.resolve(os.Getenv)
+			if err != nil {
+				return fmt.Errorf("profile %s: %v", profile.Name, err)
+			}
+			o.Auth = auth
+		}
+		o.Production = profile.Production
+		o.AllowDestructive = profile.AllowDestructive
+		return nil
+	}
+}
+
+func WithAuth(auth *Auth) Option {
+	return func(o *ClientOptions) error {
+		resolved, err := auth.resolve(os.Getenv)
+		if err != nil {
+			return err
+		}
+		o.Auth = resolved
+		return nil
+	}
+}
+
+// Allows destructive operations against production, e.g. for a confirmed cleanup.
+func WithAllowDestructive(allow bool) Option {
+	return func(o *ClientOptions) error {
+		o.AllowDestructive = allow
+		return nil
+	}
+}
+
+// Whether the client talks to production.
+func (c *Client) Production() bool {
+	return c.production
+}
+
+// Fails with ErrDestructiveOperation when the operation is not allowed for the client.
+func (c *Client) CheckDestructive(operation string) error {
+	if c.production && !c.allowDestructive {
+		return fmt.Errorf("%s: %w", operation, ErrDestructiveOperation)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "form3")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "profiles.yaml")
+	_ = ioutil.WriteFile(file, []byte(`default_profile: local
+profiles:
+  local:
+    base_url: http://localhost:8080/v1
+  production:
+    base_url: https://api.example.com/v1
+    timeout: 10s
+    production: true
+    rate_limit: {rate: 50, burst: 10}
+    auth: {method: basic, username: user, password_env: FORM3_TEST_PASSWORD}
+`), 0600)
+
+	profiles, err := LoadProfiles(file)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"local", "production"}, profiles.Names())
+
+	profile, err := profiles.Select("")
+	assert.Nil(t, err)
+	assert.Equal(t, &Profile{Name: "local", BaseURL: "http://localhost:8080/v1"}, profile)
+
+	profile, err = profiles.Select("production")
+	assert.Nil(t, err)
+	assert.Equal(t, &Profile{
+		Name:       "production",
+		BaseURL:    "https://api.example.com/v1",
+		Timeout:    10 * time.Second,
+		Production: true,
+		RateLimit:  &RateLimit{Rate: 50, Burst: 10},
+		Auth:       &Auth{Method: AuthBasic, Username: "user", PasswordEnv: "FORM3_TEST_PASSWORD"},
+	}, profile)
+
+	_, err = profiles.Select("staging")
+	assert.EqualError(t, err, `unknown profile "staging", configured: [local production]`)
+
+	profile, err = (&Profiles{}).Select("")
+	assert.Nil(t, err)
+	assert.Equal(t, LocalProfile, profile)
+}
+
+func TestWithProfile_Auth(t *testing.T) {
+	var username, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	os.Setenv("FORM3_TEST_PASSWORD", "secret")
+	defer os.Unsetenv("FORM3_TEST_PASSWORD")
+
+	client, err := NewClient(WithProfile(&Profile{
+		Name:    "staging",
+		BaseURL: server.URL,
+		Auth:    &Auth{Method: AuthBasic, Username: "user", PasswordEnv: "FORM3_TEST_PASSWORD"},
+	}))
+	assert.Nil(t, err)
+
+	_, _, err = CreateAccountsService(client).List(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "secret", password)
+
+	_, err = NewClient(WithProfile(&Profile{Name: "staging", Auth: &Auth{Method: AuthBearer, TokenEnv: "FORM3_TEST_MISSING"}}))
+	assert.EqualError(t, err, "profile staging: bearer auth requires a token")
+
+	_, err = NewClient(WithAuth(&Auth{Method: "oauth"}))
+	assert.EqualError(t, err, `unknown auth method "oauth"`)
+}
+
+func TestDestructiveGuard(t *testing.T) {
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deletes++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	production := &Profile{Name: "production", BaseURL: server.URL, Production: true}
+	client, _ := NewClient(WithProfile(production))
+	assert.True(t, client.Production())
+
+	ctx := context.Background()
+	_, err := CreateAccountsService(client).Delete(ctx, "1", 0)
+	assert.True(t, errors.Is(err, ErrDestructiveOperation))
+	assert.EqualError(t, err, "accounts.Delete: destructive operation refused against production")
+	_, err = CreateOrganisationsService(client).Delete(ctx, "1", 0)
+	assert.True(t, errors.Is(err, ErrDestructiveOperation))
+	_, err = CreateSubscriptionsService(client).Delete(ctx, "1", 0)
+	assert.True(t, errors.Is(err, ErrDestructiveOperation))
+	_, _, err = CreateMandatesService(client).Cancel(ctx, "1", "2", "closed")
+	assert.EqualError(t, err, "mandates.Cancel: destructive operation refused against production")
+	_, _, err = CreateDirectDebitsService(client).Return(ctx, "1", "2", "AC01")
+	assert.EqualError(t, err, "directdebits.Return: destructive operation refused against production")
+	assert.Equal(t, 0, deletes)
+
+	client, _ = NewClient(WithProfile(production), WithAllowDestructive(true))
+	_, err = CreateAccountsService(client).Delete(ctx, "1", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deletes)
+}
@@ -0,0 +1,278 @@
+package form3
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits traffic sent to the API, zero values mean no limit.
+type RateLimit struct {
+	// Requests per second
+	Rate float64 `yaml:"rate"`
+
+	// Requests allowed at once above the rate, 1 when not set
+	Burst int `yaml:"burst"`
+
+	// Requests sent but not yet completed
+	MaxInFlight int `yaml:"max_in_flight"`
+}
+
+// Token bucket, refilled at rate tokens per second up to burst.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Takes a token and returns how long to wait before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if blocked := b.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+
+	return wait
+}
+
+// Blocks until a token is available or ctx is done, the token is given back when ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	wait := b.reserve()
+	if wait <= 0 {
+		if err := ctx.Err(); err != nil {
+			b.refund()
+			return err
+		}
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.refund()
+		return ctx.Err()
+	}
+}
+
+// Gives back a reserved token that was not used.
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// Follows X-RateLimit-Remaining and X-RateLimit-Reset response headers:
+// no more tokens than remaining, and none at all until reset once exhausted.
+// Reset is either seconds until the reset or a unix timestamp.
+func (b *tokenBucket) observe(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.tokens, float64(remaining))
+	if remaining > 0 {
+		return
+	}
+
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	now := b.now()
+	if reset > 1e9 {
+		b.blockedUntil = time.Unix(reset, 0)
+	} else {
+		b.blockedUntil = now.Add(time.Duration(reset) * time.Second)
+	}
+}
+
+type limiter struct {
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+func newLimiter(limit *RateLimit) *limiter {
+	l := &limiter{}
+	if limit.Rate > 0 {
+		l.bucket = newTokenBucket(limit.Rate, limit.Burst)
+	}
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return l
+}
+
+func (l *limiter) acquire(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if l.bucket != nil {
+		if err := l.bucket.wait(ctx); err != nil {
+			l.release()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+func (l *limiter) observe(header http.Header) {
+	if l.bucket != nil {
+		l.bucket.observe(header)
+	}
+}
+
+// Applies the client wide limit and the limit of the requested endpoint.
+// Endpoints are keyed by method and path relative to the base endpoint,
+// e.g. "POST /organisation/accounts"; a trailing /* matches any sub path.
+type rateLimitTransport struct {
+	next      http.RoundTripper
+	basePath  string
+	global    *limiter
+	endpoints map[string]*limiter
+
+	// sorted keys of endpoints, limiters are acquired in this order so
+	// requests matching several endpoints cannot deadlock on MaxInFlight
+	order []string
+}
+
+func newRateLimitTransport(next http.RoundTripper, basePath string, global *RateLimit, endpoints map[string]*RateLimit) *rateLimitTransport {
+	t := &rateLimitTransport{
+		next:      next,
+		basePath:  strings.TrimRight(basePath, "/"),
+		endpoints: make(map[string]*limiter, len(endpoints)),
+	}
+	if global != nil {
+		t.global = newLimiter(global)
+	}
+	for endpoint, limit := range endpoints {
+		t.endpoints[endpoint] = newLimiter(limit)
+		t.order = append(t.order, endpoint)
+	}
+	sort.Strings(t.order)
+
+	return t
+}
+
+func (t *rateLimitTransport) limiters(req *http.Request) []*limiter {
+	var limiters []*limiter
+	if t.global != nil {
+		limiters = append(limiters, t.global)
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, t.basePath)
+	for _, endpoint := range t.order {
+		parts := strings.SplitN(endpoint, " ", 2)
+		if len(parts) != 2 || parts[0] != req.Method {
+			continue
+		}
+
+		pattern := parts[1]
+		if pattern == path || (strings.HasSuffix(pattern, "/*") && strings.HasPrefix(path, strings.TrimSuffix(pattern, "*"))) {
+			limiters = append(limiters, t.endpoints[endpoint])
+		}
+	}
+
+	return limiters
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiters := t.limiters(req)
+	release := func(n int) {
+		for _, l := range limiters[:n] {
+			l.release()
+		}
+	}
+
+	for i, l := range limiters {
+		if err := l.acquire(req.Context()); err != nil {
+			release(i)
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release(len(limiters))
+		return nil, err
+	}
+
+	for _, l := range limiters {
+		l.observe(resp.Header)
+	}
+
+	// the request stays in flight until its body is read and closed
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { release(len(limiters)) }}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,170 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestBucket(rate float64, burst int) (*tokenBucket, *time.Time) {
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(rate, burst)
+	bucket.now = func() time.Time { return now }
+	return bucket, &now
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	bucket, clock := newTestBucket(10, 2)
+
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+
+	*clock = clock.Add(time.Second)
+	assert.Equal(t, time.Duration(0), bucket.reserve(), "bucket refills up to burst")
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+}
+
+func TestTokenBucket_ObserveHeaders(t *testing.T) {
+	bucket, clock := newTestBucket(100, 10)
+
+	bucket.observe(http.Header{"X-Ratelimit-Remaining": {"1"}})
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 10*time.Millisecond, bucket.reserve(), "tokens are capped to remaining")
+
+	bucket.observe(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"2"}})
+	assert.Equal(t, 2*time.Second, bucket.reserve())
+
+	reset := clock.Add(5 * time.Second).Unix()
+	bucket.observe(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {fmt.Sprint(reset)}})
+	assert.Equal(t, 5*time.Second, bucket.reserve())
+}
+
+func TestTokenBucket_WaitHonoursContext(t *testing.T) {
+	bucket := newTokenBucket(0.001, 1)
+	bucket.reserve()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, bucket.wait(ctx))
+}
+
+func TestTokenBucket_WaitRefundsOnCancel(t *testing.T) {
+	bucket, _ := newTestBucket(10, 1)
+	bucket.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, bucket.wait(ctx))
+	assert.Equal(t, context.Canceled, bucket.wait(ctx))
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve(), "cancelled waits do not delay later callers")
+}
+
+func TestRateLimitTransport_MaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		_, _ = fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer server.Close()
+
+	client := CreateClient(&ClientOptions{
+		Timeout:      3000,
+		BaseEndpoint: server.URL,
+		RateLimit:    &RateLimit{MaxInFlight: 2},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := client.GET("/x", nil)
+			_, err := client.Do(context.Background(), req, nil)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestRateLimitTransport_EndpointLimits(t *testing.T) {
+	transport := newRateLimitTransport(http.DefaultTransport, "/v1/", &RateLimit{Rate: 100}, map[string]*RateLimit{
+		"POST /organisation/accounts":     {Rate: 1},
+		"DELETE /organisation/accounts/*": {MaxInFlight: 1},
+	})
+
+	limiters := func(method, path string) []*limiter {
+		req, _ := http.NewRequest(method, "http://localhost:8080/v1"+path, nil)
+		return transport.limiters(req)
+	}
+
+	assert.Equal(t, []*limiter{transport.global}, limiters("GET", "/organisation/accounts"))
+	assert.Equal(
+		t,
+		[]*limiter{transport.global, transport.endpoints["POST /organisation/accounts"]},
+		limiters("POST", "/organisation/accounts"),
+	)
+	assert.Equal(
+		t,
+		[]*limiter{transport.global, transport.endpoints["DELETE /organisation/accounts/*"]},
+		limiters("DELETE", "/organisation/accounts/1"),
+	)
+}
+
+func TestRateLimitTransport_LimiterOrder(t *testing.T) {
+	transport := newRateLimitTransport(http.DefaultTransport, "/v1/", nil, map[string]*RateLimit{
+		"GET /organisation/*":          {MaxInFlight: 1},
+		"GET /organisation/accounts/*": {MaxInFlight: 1},
+		"GET /organisation/accounts/1": {MaxInFlight: 1},
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:8080/v1/organisation/accounts/1", nil)
+	for i := 0; i < 20; i++ {
+		limiters := transport.limiters(req)
+		assert.Len(t, limiters, 3)
+		for j, endpoint := range []string{"GET /organisation/*", "GET /organisation/accounts/*", "GET /organisation/accounts/1"} {
+			assert.Same(t, transport.endpoints[endpoint], limiters[j])
+		}
+	}
+}
+
+func TestRateLimitTransport_BlocksOnCallerContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer server.Close()
+
+	client := CreateClient(&ClientOptions{
+		Timeout:      3000,
+		BaseEndpoint: server.URL,
+		RateLimit:    &RateLimit{Rate: 0.001},
+	})
+
+	req, _ := client.GET("/x", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ = client.GET("/x", nil)
+	_, err = client.Do(ctx, req, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+}
@@ -0,0 +1,89 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Responds with the statuses in order, 0 for a transport error.
+type sequenceTransport struct {
+	statuses []int
+	bodies   []string
+}
+
+func (s *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		b, _ := ioutil.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(b))
+	}
+
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	if status == 0 {
+		return nil, errors.New("connection reset")
+	}
+
+	header := http.Header{}
+	if status == http.StatusTooManyRequests {
+		header.Set("Retry-After", "1")
+	}
+
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader("")), Header: header}, nil
+}
+
+func newTestRetryTransport(statuses ...int) (*retryTransport, *sequenceTransport) {
+	stub := &sequenceTransport{statuses: statuses}
+	return newRetryTransport(stub, &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}), stub
+}
+
+func TestRetryTransport(t *testing.T) {
+	transport, _ := newTestRetryTransport(0, http.StatusTooManyRequests, http.StatusOK)
+	req, _ := http.NewRequest("GET", "http://a/x", nil)
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	transport, _ = newTestRetryTransport(http.StatusServiceUnavailable)
+	resp, err = transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	transport, _ = newTestRetryTransport(http.StatusInternalServerError, http.StatusOK)
+	resp, _ = transport.RoundTrip(req)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestRetryTransport_ReplaysBody(t *testing.T) {
+	transport, stub := newTestRetryTransport(http.StatusBadGateway, http.StatusOK)
+	req, _ := http.NewRequest("DELETE", "http://a/x", strings.NewReader("payload"))
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, stub.bodies)
+}
+
+func TestRetryTransport_NotIdempotent(t *testing.T) {
+	transport, _ := newTestRetryTransport(http.StatusServiceUnavailable, http.StatusOK)
+	req, _ := http.NewRequest("POST", "http://a/x", strings.NewReader("{}"))
+	resp, _ := transport.RoundTrip(req)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestRetryTransport_Context(t *testing.T) {
+	stub := &sequenceTransport{statuses: []int{http.StatusServiceUnavailable}}
+	transport := newRetryTransport(stub, &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://a/x", nil)
+	_, err := transport.RoundTrip(req)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,127 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Returned by organisation scoped services for resources of another organisation.
+var ErrOtherOrganisation = errors.New("resource belongs to another organisation")
+
+// Services of a client scoped to one organisation: created resources are stamped
+// with it, fetched resources of other organisations are rejected and lists are
+// filtered by it.
+type OrganisationScope struct {
+	OrganisationID string
+
+	Client        *Client
+	Accounts      *AccountsService
+	Mandates      *MandatesService
+	DirectDebits  *DirectDebitsService
+	Subscriptions *SubscriptionsService
+}
+
+// Services scoped to the organisation, sharing the transport of c.
+func (c *Client) ForOrganisation(orgID string) *OrganisationScope {
+	scoped := *c
+	scoped.defaultOrganisationID = orgID
+	scoped.scopeOrganisationID = orgID
+
+	return newOrganisationScope(&scoped)
+}
+
+func newOrganisationScope(client *Client) *OrganisationScope {
+	return &OrganisationScope{
+		OrganisationID: client.scopeOrganisationID,
+		Client:         client,
+		Accounts:       CreateAccountsService(client),
+		Mandates:       CreateMandatesService(client),
+		DirectDebits:   CreateDirectDebitsService(client),
+		Subscriptions:  CreateSubscriptionsService(client),
+	}
+}
+
+// Organisation the client is scoped to, empty when it is not scoped.
+func (c *Client) ScopeOrganisationID() string {
+	return c.scopeOrganisationID
+}
+
+// Organisation of a resource being created: the default one when empty,
+// an error when it is outside the scope.
+func (c *Client) stampOrganisation(orgID string) (string, error) {
+	if orgID == "" {
+		return c.defaultOrganisationID, nil
+	}
+
+	return orgID, c.checkOrganisation(orgID)
+}
+
+// Fails when a resource of the organisation is outside the scope.
+func (c *Client) checkOrganisation(orgID string) error {
+	if c.scopeOrganisationID != "" && orgID != c.scopeOrganisationID {
+		return fmt.Errorf("%w: %q, scoped to %q", ErrOtherOrganisation, orgID, c.scopeOrganisationID)
+	}
+
+	return nil
+}
+
+// Adds the organisation filter of the scope, options are not modified.
+func (c *Client) scopeListOptions(options *ListOptions) *ListOptions {
+	if c.scopeOrganisationID == "" {
+		return options
+	}
+
+	scoped := ListOptions{}
+	if options != nil {
+		scoped = *options
+	}
+	filter := make(map[string]string, len(scoped.Filter)+1)
+	for k, v := range scoped.Filter {
+		filter[k] = v
+	}
+	filter["organisation_id"] = c.scopeOrganisationID
+	scoped.Filter = filter
+
+	return &scoped
+}
+
+// Organisation scoped clients of many tenants, sharing one transport and its connections.
+type ClientPool struct {
+	client *Client
+
+	mu     sync.Mutex
+	scopes map[string]*OrganisationScope
+}
+
+// Creates pool of clients configured by opts.
+func NewClientPool(opts ...Option) (*ClientPool, error) {
+	client, err := NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientPool{client: client, scopes: make(map[string]*OrganisationScope)}, nil
+}
+
+// Services scoped to the organisation, created on first use.
+func (p *ClientPool) ForOrganisation(orgID string) *OrganisationScope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	scope, ok := p.scopes[orgID]
+	if !ok {
+		scope = p.client.ForOrganisation(orgID)
+		p.scopes[orgID] = scope
+	}
+
+	return scope
+}
+
+// Organisations with scoped clients in the pool.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.scopes)
+}
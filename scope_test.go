@@ -0,0 +1,112 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestForOrganisation_Create(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		testBody(t, r, `{"data":{"id":"1","organisation_id":"org","type":"accounts","version":0}}`)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"data": {"id": "1", "organisation_id": "org"}}`)
+	})
+
+	scope := client.ForOrganisation("org")
+	assert.Equal(t, "org", scope.Client.ScopeOrganisationID())
+	assert.Equal(t, "", client.ScopeOrganisationID())
+
+	account := MakeAccount("1", "")
+	_, _, err := scope.Accounts.Create(context.Background(), account)
+	assert.Nil(t, err)
+	assert.Equal(t, "", account.OrganisationID)
+
+	_, _, err = scope.Accounts.Create(context.Background(), MakeAccount("1", "other"))
+	assert.True(t, errors.Is(err, ErrOtherOrganisation))
+	assert.EqualError(t, err, `resource belongs to another organisation: "other", scoped to "org"`)
+
+	_, _, err = scope.Mandates.Create(context.Background(), MakeMandate("1", "other", "2"))
+	assert.True(t, errors.Is(err, ErrOtherOrganisation))
+}
+
+func TestForOrganisation_ByID(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"id": "1", "organisation_id": "other"}}`)
+	})
+	mux.HandleFunc("/transaction/mandates/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"id": "1", "organisation_id": "org"}}`)
+	})
+
+	scope := client.ForOrganisation("org")
+	account, resp, err := scope.Accounts.ByID(context.Background(), "1")
+	assert.Nil(t, account)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, errors.Is(err, ErrOtherOrganisation))
+
+	mandate, _, err := scope.Mandates.ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "org", mandate.OrganisationID)
+
+	account, _, err = CreateAccountsService(client).ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "other", account.OrganisationID)
+}
+
+func TestForOrganisation_List(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "org", r.URL.Query().Get("filter[organisation_id]"))
+		assert.Equal(t, "GB", r.URL.Query().Get("filter[country]"))
+		fmt.Fprint(w, `{"data": [{"id": "1", "organisation_id": "org"}]}`)
+	})
+	mux.HandleFunc("/transaction/directdebits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": [{"id": "1", "organisation_id": "org"}, {"id": "2", "organisation_id": "other"}]}`)
+	})
+
+	scope := client.ForOrganisation("org")
+	options := &ListOptions{Filter: map[string]string{"country": "GB"}}
+	accounts, _, err := scope.Accounts.List(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, map[string]string{"country": "GB"}, options.Filter)
+
+	_, _, err = scope.DirectDebits.List(context.Background(), nil)
+	assert.True(t, errors.Is(err, ErrOtherOrganisation))
+}
+
+func TestClientPool(t *testing.T) {
+	pool, err := NewClientPool(WithBaseURL("http://localhost:8080/v1"))
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pool.ForOrganisation(fmt.Sprintf("org-%d", i%3))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, pool.Len())
+	a, b := pool.ForOrganisation("org-0"), pool.ForOrganisation("org-1")
+	assert.Same(t, a, pool.ForOrganisation("org-0"))
+	assert.Same(t, a.Client.httpClient, b.Client.httpClient)
+	assert.Equal(t, "org-1", b.OrganisationID)
+
+	_, err = NewClientPool(WithBaseURL("localhost"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,146 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	subscriptionsBaseEndpoint = "/notification/subscriptions"
+)
+
+// How notifications are delivered to the callback URI.
+type CallbackTransport string
+
+const (
+	CallbackTransportHTTP  CallbackTransport = "http"
+	CallbackTransportQueue CallbackTransport = "queue"
+)
+
+// Type of the record a notification is sent for.
+type RecordType string
+
+const (
+	RecordTypeAccounts RecordType = "accounts"
+)
+
+// Type of the change a notification is sent for.
+type EventType string
+
+const (
+	EventTypeCreated EventType = "created"
+	EventTypeUpdated EventType = "updated"
+	EventTypeDeleted EventType = "deleted"
+)
+
+type SubscriptionsService service
+
+type Subscription struct {
+	ID             string                  `json:"id"`
+	OrganisationID string                  `json:"organisation_id"`
+	Type           string                  `json:"type"`
+	Attributes     *SubscriptionAttributes `json:"attributes,omitempty"`
+	Version        int                     `json:"version"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+}
+
+type SubscriptionAttributes struct {
+	CallbackURI       string            `json:"callback_uri"`
+	CallbackTransport CallbackTransport `json:"callback_transport"`
+	EventType         EventType         `json:"event_type"`
+	RecordType        RecordType        `json:"record_type"`
+	Deactivated       bool              `json:"deactivated"`
+}
+
+// Build new subscription for the record and event type.
+func MakeSubscription(id, orgID string, recordType RecordType, eventType EventType) *Subscription {
+	return &Subscription{
+		ID:             id,
+		OrganisationID: orgID,
+		Type:           "subscriptions",
+		Attributes: &SubscriptionAttributes{
+			EventType:  eventType,
+			RecordType: recordType,
+		},
+	}
+}
+
+// Creates new subscription.
+func (s *SubscriptionsService) Create(ctx context.Context, data *Subscription, opts ...CallOption) (*Subscription, *Response, error) {
+	if data == nil {
+		return nil, nil, ErrNilData
+	}
+
+	ctx = withOperation(ctx, "subscriptions.Create", opts...)
+	ctx = withResourceID(ctx, data.ID)
+	client := s.client
+
+	orgID, err := client.stampOrganisation(data.OrganisationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if orgID != data.OrganisationID {
+		stamped := *data
+		stamped.OrganisationID = orgID
+		data = &stamped
+	}
+
+	req, err := client.POST(subscriptionsBaseEndpoint, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subscription := new(Subscription)
+	resp, err := client.Do(ctx, req, subscription)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return subscription, resp, nil
+}
+
+// Get list of subscriptions.
+func (s *SubscriptionsService) List(ctx context.Context, options *ListOptions, opts ...CallOption) ([]*Subscription, *Response, error) {
+	ctx = withOperation(ctx, "subscriptions.List", opts...)
+	client := s.client
+
+	req, err := client.GET(addListOptions(subscriptionsBaseEndpoint, client.scopeListOptions(options)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subscriptions := new([]*Subscription)
+	resp, err := client.Do(ctx, req, subscriptions)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, item := range *subscriptions {
+		if err := client.checkOrganisation(item.OrganisationID); err != nil {
+			return nil, resp, err
+		}
+	}
+
+	return *subscriptions, resp, nil
+}
+
+// Delete subscription by id and version
+func (s *SubscriptionsService) Delete(ctx context.Context, id string, version int, opts ...CallOption) (*Response, error) {
+	ctx = withOperation(ctx, "subscriptions.Delete", opts...)
+	ctx = withResourceID(ctx, id)
+	client := s.client
+
+	if err := client.CheckDestructive("subscriptions.Delete"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%s?version=%d", subscriptionsBaseEndpoint, id, version)
+
+	req, err := client.DELETE(url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(ctx, req, nil)
+}
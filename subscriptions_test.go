@@ -0,0 +1,74 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func setupSubscriptions() (service *SubscriptionsService, mux *http.ServeMux, serverURL string, teardown func()) {
+	client, mux, serverURL, teardown := setup()
+	service = CreateSubscriptionsService(client)
+	return service, mux, serverURL, teardown
+}
+
+func TestSubscriptionsService_Create(t *testing.T) {
+	service, mux, _, teardown := setupSubscriptions()
+	defer teardown()
+
+	mux.HandleFunc("/notification/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"data":{"id":"1","organisation_id":"2","type":"subscriptions","attributes":`+
+			`{"callback_uri":"https://example.com/hook","callback_transport":"http","event_type":"created",`+
+			`"record_type":"accounts","deactivated":false},"version":0}}`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"subscriptions","attributes":`+
+			`{"callback_uri":"https://example.com/hook","callback_transport":"http","event_type":"created",`+
+			`"record_type":"accounts","deactivated":false},"version":0}}`)
+	})
+
+	subscription := MakeSubscription("1", "2", RecordTypeAccounts, EventTypeCreated)
+	subscription.Attributes.CallbackURI = "https://example.com/hook"
+	subscription.Attributes.CallbackTransport = CallbackTransportHTTP
+
+	saved, resp, err := service.Create(context.Background(), subscription)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, subscription, saved)
+}
+
+func TestSubscriptionsService_List(t *testing.T) {
+	service, mux, _, teardown := setupSubscriptions()
+	defer teardown()
+
+	mux.HandleFunc("/notification/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		_, _ = fmt.Fprint(w, `{"data":[`+
+			`{"id":"1","type":"subscriptions","attributes":{"event_type":"created","record_type":"accounts"}},`+
+			`{"id":"2","type":"subscriptions","attributes":{"event_type":"deleted","record_type":"accounts"}}]}`)
+	})
+
+	list, resp, err := service.List(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, EventTypeDeleted, list[1].Attributes.EventType)
+	assert.Equal(t, RecordTypeAccounts, list[1].Attributes.RecordType)
+}
+
+func TestSubscriptionsService_Delete(t *testing.T) {
+	service, mux, _, teardown := setupSubscriptions()
+	defer teardown()
+
+	mux.HandleFunc("/notification/subscriptions/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		assert.Equal(t, "0", r.URL.Query().Get("version"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := service.Delete(context.Background(), "1", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+}
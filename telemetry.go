@@ -0,0 +1,370 @@
+package form3
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Names of span attributes and metrics follow OpenTelemetry semantic conventions for HTTP clients.
+const (
+	AttributeHTTPMethod     = "http.request.method"
+	AttributeHTTPStatusCode = "http.response.status_code"
+	AttributeURLFull        = "url.full"
+	AttributeServerAddress  = "server.address"
+	AttributeServerPort     = "server.port"
+	AttributeErrorType      = "error.type"
+	AttributeOperation      = "form3.operation"
+
+	MetricRequestDuration = "http.client.request.duration"
+	MetricRequests        = "form3.client.requests"
+
+	// W3C trace context header
+	TraceParentHeader = "traceparent"
+)
+
+// Upper bounds in seconds of the request duration histogram buckets.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type TelemetryOptions struct {
+	// Receives finished spans, no spans are recorded when nil
+	SpanExporter SpanExporter
+
+	// Receives request measurements, no metrics are recorded when nil
+	MetricExporter MetricExporter
+
+	// Starts the span of a request in another tracer, e.g. OpenTelemetry, taking the parent
+	// from ctx. It returns the traceparent of the started span, sent with the request so
+	// server spans are parented to it, and a function ending the span with the finished data.
+	// Random IDs and ContextWithTraceParent are used when nil. With OpenTelemetry:
+	//
+	//	func(ctx context.Context, name string) (string, func(*form3.SpanData)) {
+	//		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	//		carrier := propagation.MapCarrier{}
+	//		propagation.TraceContext{}.Inject(ctx, carrier)
+	//		return carrier.Get(form3.TraceParentHeader), func(s *form3.SpanData) {
+	//			for k, v := range s.Attributes {
+	//				span.SetAttributes(attribute.String(k, fmt.Sprint(v)))
+	//			}
+	//			if s.Error != "" {
+	//				span.SetStatus(codes.Error, s.Error)
+	//			}
+	//			span.End()
+	//		}
+	//	}
+	StartSpan func(ctx context.Context, name string) (traceParent string, end func(span *SpanData))
+}
+
+// Finished span of a request, from sending it until its response body is closed.
+type SpanData struct {
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+
+	// Error message when the request failed or the status code is 5xx
+	Error string
+}
+
+// Receives finished spans. To record them with OpenTelemetry, use TelemetryOptions.StartSpan,
+// so the IDs of the recorded spans match the traceparent sent.
+type SpanExporter interface {
+	ExportSpan(span *SpanData)
+}
+
+type SpanExporterFunc func(span *SpanData)
+
+func (f SpanExporterFunc) ExportSpan(span *SpanData) {
+	f(span)
+}
+
+// Measurement of a single request, the duration ends when the response body is closed.
+type Measurement struct {
+	Operation  string
+	Method     string
+	StatusCode int
+	Duration   time.Duration
+
+	// Set when no response was received
+	ErrorType string
+}
+
+// Receives request measurements, passed to an OpenTelemetry histogram with an adapter, e.g.
+//
+//	form3.MetricExporterFunc(func(m *form3.Measurement) {
+//		duration.Record(context.Background(), m.Duration.Seconds(), metric.WithAttributes(
+//			attribute.String(form3.AttributeOperation, m.Operation),
+//			attribute.Int(form3.AttributeHTTPStatusCode, m.StatusCode),
+//		))
+//	})
+type MetricExporter interface {
+	Record(m *Measurement)
+}
+
+type MetricExporterFunc func(m *Measurement)
+
+func (f MetricExporterFunc) Record(m *Measurement) {
+	f(m)
+}
+
+type spanContextKey struct{}
+
+type spanContext struct {
+	traceID string
+	spanID  string
+	flags   string
+}
+
+var traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// Continues the trace of the traceparent header, e.g. of an incoming request,
+// requests made with the returned context are recorded as its children.
+// Not used with TelemetryOptions.StartSpan, which takes the parent from its own tracer.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	m := traceParentPattern.FindStringSubmatch(traceParent)
+	if m == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, &spanContext{traceID: m[1], spanID: m[2], flags: m[3]})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type telemetryTransport struct {
+	next      http.RoundTripper
+	spans     SpanExporter
+	metrics   MetricExporter
+	startSpan func(ctx context.Context, name string) (string, func(*SpanData))
+	now       func() time.Time
+}
+
+func newTelemetryTransport(next http.RoundTripper, options *TelemetryOptions) *telemetryTransport {
+	return &telemetryTransport{
+		next:      next,
+		spans:     options.SpanExporter,
+		metrics:   options.MetricExporter,
+		startSpan: options.StartSpan,
+		now:       time.Now,
+	}
+}
+
+func (t *telemetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	operation := OperationFromContext(ctx)
+	name := operation
+	if name == "" {
+		name = "HTTP " + req.Method
+	}
+
+	span := &SpanData{
+		Name:   name,
+		SpanID: randomHex(8),
+		Start:  t.now(),
+		Attributes: map[string]interface{}{
+			AttributeHTTPMethod:    req.Method,
+			AttributeURLFull:       DefaultRedactor.RedactURL(req.URL),
+			AttributeServerAddress: req.URL.Hostname(),
+		},
+	}
+	if port, err := strconv.Atoi(req.URL.Port()); err == nil {
+		span.Attributes[AttributeServerPort] = port
+	}
+	if operation != "" {
+		span.Attributes[AttributeOperation] = operation
+	}
+
+	flags := "01"
+	var end func(*SpanData)
+	if t.startSpan != nil {
+		var traceParent string
+		traceParent, end = t.startSpan(ctx, name)
+		if m := traceParentPattern.FindStringSubmatch(traceParent); m != nil {
+			span.TraceID, span.SpanID, flags = m[1], m[2], m[3]
+		}
+	}
+	if span.TraceID == "" {
+		if parent, ok := ctx.Value(spanContextKey{}).(*spanContext); ok {
+			span.TraceID, span.ParentSpanID, flags = parent.traceID, parent.spanID, parent.flags
+		} else {
+			span.TraceID = randomHex(16)
+		}
+	}
+
+	// the request is cloned, RoundTrippers must not modify the original
+	req = req.Clone(ctx)
+	req.Header.Set(TraceParentHeader, fmt.Sprintf("00-%s-%s-%s", span.TraceID, span.SpanID, flags))
+
+	resp, err := t.next.RoundTrip(req)
+
+	measurement := &Measurement{Operation: operation, Method: req.Method}
+	if err != nil {
+		span.Error = err.Error()
+		span.Attributes[AttributeErrorType] = fmt.Sprintf("%T", err)
+		measurement.ErrorType = fmt.Sprintf("%T", err)
+	} else {
+		span.Attributes[AttributeHTTPStatusCode] = resp.StatusCode
+		measurement.StatusCode = resp.StatusCode
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.Error = resp.Status
+			span.Attributes[AttributeErrorType] = strconv.Itoa(resp.StatusCode)
+		}
+	}
+
+	finish := func() {
+		span.End = t.now()
+		measurement.Duration = span.End.Sub(span.Start)
+		if end != nil {
+			end(span)
+		}
+		if t.spans != nil {
+			t.spans.ExportSpan(span)
+		}
+		if t.metrics != nil {
+			t.metrics.Record(measurement)
+		}
+	}
+
+	// the span covers reading the body, it ends when the body is closed
+	if err != nil || resp.Body == nil {
+		finish()
+		return resp, err
+	}
+	resp.Body = &spanBody{body: resp.Body, span: span, finish: finish}
+
+	return resp, err
+}
+
+// Response body ending the span when it is closed.
+type spanBody struct {
+	body   io.ReadCloser
+	span   *SpanData
+	once   sync.Once
+	finish func()
+}
+
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if err != nil && err != io.EOF && b.span.Error == "" {
+		b.span.Error = err.Error()
+		b.span.Attributes[AttributeErrorType] = fmt.Sprintf("%T", err)
+	}
+
+	return n, err
+}
+
+func (b *spanBody) Close() error {
+	err := b.body.Close()
+	b.once.Do(b.finish)
+	return err
+}
+
+// Keeps finished spans in memory, meant for tests.
+type InMemorySpanExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+func (e *InMemorySpanExporter) ExportSpan(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Exported spans in the order they finished.
+func (e *InMemorySpanExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*SpanData(nil), e.spans...)
+}
+
+// Key of aggregated measurements: operation and status code, 0 when no response was received.
+type MetricKey struct {
+	Operation  string
+	StatusCode int
+}
+
+// Request duration histogram.
+type Histogram struct {
+	// Upper bounds in seconds, the last count is for durations above all of them
+	Bounds []float64
+	Counts []uint64
+	Sum    time.Duration
+	Count  uint64
+}
+
+// Aggregates measurements into request counters and duration histograms in memory.
+type InMemoryMetricExporter struct {
+	// Histogram bucket bounds, DefaultDurationBuckets when nil
+	Buckets []float64
+
+	mu         sync.Mutex
+	histograms map[MetricKey]*Histogram
+}
+
+func (e *InMemoryMetricExporter) Record(m *Measurement) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bounds := e.Buckets
+	if bounds == nil {
+		bounds = DefaultDurationBuckets
+	}
+	if e.histograms == nil {
+		e.histograms = make(map[MetricKey]*Histogram)
+	}
+
+	key := MetricKey{Operation: m.Operation, StatusCode: m.StatusCode}
+	h, ok := e.histograms[key]
+	if !ok {
+		h = &Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+		e.histograms[key] = h
+	}
+
+	h.Counts[sort.SearchFloat64s(bounds, m.Duration.Seconds())]++
+	h.Sum += m.Duration
+	h.Count++
+}
+
+// Number of requests by operation and status code.
+func (e *InMemoryMetricExporter) Counts() map[MetricKey]uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	counts := make(map[MetricKey]uint64, len(e.histograms))
+	for key, h := range e.histograms {
+		counts[key] = h.Count
+	}
+
+	return counts
+}
+
+// Duration histogram of the operation and status code, nil when nothing was recorded.
+func (e *InMemoryMetricExporter) Histogram(key MetricKey) *Histogram {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	h, ok := e.histograms[key]
+	if !ok {
+		return nil
+	}
+
+	clone := *h
+	clone.Counts = append([]uint64(nil), h.Counts...)
+	return &clone
+}
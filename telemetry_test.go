@@ -0,0 +1,180 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func setupTelemetry() (service *AccountsService, mux *http.ServeMux, spans *InMemorySpanExporter, metrics *InMemoryMetricExporter, teardown func()) {
+	client, mux, serverURL, teardown := setup()
+	spans = &InMemorySpanExporter{}
+	metrics = &InMemoryMetricExporter{}
+
+	instrumented := CreateClient(&ClientOptions{
+		Timeout:      3000,
+		BaseEndpoint: serverURL,
+		Telemetry:    &TelemetryOptions{SpanExporter: spans, MetricExporter: metrics},
+	})
+	instrumented.BaseURL = client.BaseURL
+
+	return CreateAccountsService(instrumented), mux, spans, metrics, teardown
+}
+
+func TestTelemetry_RecordsSpan(t *testing.T) {
+	service, mux, spans, _, teardown := setupTelemetry()
+	defer teardown()
+
+	var traceParent string
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(TraceParentHeader)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1","organisation_id":"2","type":"accounts","version":0}}`)
+	})
+
+	_, _, err := service.Create(context.Background(), MakeAccount("1", "2"))
+	assert.Nil(t, err)
+
+	recorded := spans.Spans()
+	assert.Equal(t, 1, len(recorded))
+	span := recorded[0]
+	assert.Equal(t, "accounts.Create", span.Name)
+	assert.Equal(t, "", span.Error)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), span.TraceID)
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", span.TraceID, span.SpanID), traceParent)
+
+	u, _ := url.Parse(span.Attributes[AttributeURLFull].(string))
+	assert.Equal(t, "POST", span.Attributes[AttributeHTTPMethod])
+	assert.Equal(t, 201, span.Attributes[AttributeHTTPStatusCode])
+	assert.Equal(t, "accounts.Create", span.Attributes[AttributeOperation])
+	assert.Equal(t, u.Hostname(), span.Attributes[AttributeServerAddress])
+	assert.NotNil(t, span.Attributes[AttributeServerPort])
+	assert.False(t, span.End.Before(span.Start))
+}
+
+func TestTelemetry_ContinuesIncomingTrace(t *testing.T) {
+	service, mux, spans, _, teardown := setupTelemetry()
+	defer teardown()
+
+	var traceParent string
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(TraceParentHeader)
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1"}}`)
+	})
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+	ctx := ContextWithTraceParent(context.Background(), parent)
+	_, _, _ = service.ByID(ctx, "1")
+
+	span := spans.Spans()[0]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+	assert.Equal(t, fmt.Sprintf("00-4bf92f3577b34da6a3ce929d0e0e4736-%s-00", span.SpanID), traceParent)
+}
+
+func TestTelemetry_RecordsMetrics(t *testing.T) {
+	service, mux, spans, metrics, teardown := setupTelemetry()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"data":{"id":"1"}}`)
+	})
+	mux.HandleFunc("/organisation/accounts/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx := context.Background()
+	_, _, _ = service.ByID(ctx, "1")
+	_, _, _ = service.ByID(ctx, "1")
+	_, _, _ = service.ByID(ctx, "2")
+
+	assert.Equal(t, map[MetricKey]uint64{
+		{Operation: "accounts.ByID", StatusCode: 200}: 2,
+		{Operation: "accounts.ByID", StatusCode: 503}: 1,
+	}, metrics.Counts())
+
+	h := metrics.Histogram(MetricKey{Operation: "accounts.ByID", StatusCode: 200})
+	assert.Equal(t, uint64(2), h.Count)
+	assert.Equal(t, len(DefaultDurationBuckets)+1, len(h.Counts))
+	assert.Nil(t, metrics.Histogram(MetricKey{Operation: "accounts.Delete"}))
+
+	assert.Equal(t, "503 Service Unavailable", spans.Spans()[2].Error)
+}
+
+func TestInMemoryMetricExporter_Buckets(t *testing.T) {
+	metrics := &InMemoryMetricExporter{Buckets: []float64{0.1, 1}}
+	for _, d := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		metrics.Record(&Measurement{Operation: "accounts.List", StatusCode: 200, Duration: d})
+	}
+
+	h := metrics.Histogram(MetricKey{Operation: "accounts.List", StatusCode: 200})
+	assert.Equal(t, []uint64{2, 1, 1}, h.Counts)
+	assert.Equal(t, 2650*time.Millisecond, h.Sum)
+}
+
+func TestTelemetry_TransportError(t *testing.T) {
+	spans := &InMemorySpanExporter{}
+	metrics := &InMemoryMetricExporter{}
+	service := CreateAccountsServiceWithOptions(&ClientOptions{
+		Timeout:      3000,
+		BaseEndpoint: "http://localhost:1/v1",
+		Telemetry:    &TelemetryOptions{SpanExporter: spans, MetricExporter: metrics},
+	})
+
+	_, _, err := service.List(context.Background(), nil)
+	assert.NotNil(t, err)
+	assert.NotEmpty(t, spans.Spans()[0].Error)
+	assert.Equal(t, map[MetricKey]uint64{{Operation: "accounts.List"}: 1}, metrics.Counts())
+}
+
+func TestTelemetry_EndsSpanOnBodyClose(t *testing.T) {
+	spans := &InMemorySpanExporter{}
+	transport := newTelemetryTransport(&bodyTransport{body: "{}"}, &TelemetryOptions{SpanExporter: spans})
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	transport.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest("GET", "http://localhost/v1/organisation/accounts", nil)
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(spans.Spans()), "span is open while the body is read")
+
+	now = now.Add(time.Second)
+	_ = resp.Body.Close()
+	_ = resp.Body.Close()
+
+	recorded := spans.Spans()
+	assert.Equal(t, 1, len(recorded))
+	assert.Equal(t, time.Second, recorded[0].End.Sub(recorded[0].Start))
+}
+
+func TestTelemetry_StartSpanHook(t *testing.T) {
+	var started context.Context
+	var ended *SpanData
+	next := &bodyTransport{body: "{}"}
+	transport := newTelemetryTransport(next, &TelemetryOptions{
+		StartSpan: func(ctx context.Context, name string) (string, func(*SpanData)) {
+			started = ctx
+			return "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", func(span *SpanData) { ended = span }
+		},
+	})
+
+	ctx := ContextWithTraceParent(context.Background(), "00-11111111111111111111111111111111-2222222222222222-01")
+	req, _ := http.NewRequest("GET", "http://localhost/v1/organisation/accounts?filter[iban]=GB1", nil)
+	req = req.WithContext(ctx)
+
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, ctx, started)
+	assert.Nil(t, ended, "the span ends with the body")
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", next.last.Header.Get(TraceParentHeader))
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", ended.TraceID)
+	assert.Equal(t, "b7ad6b7169203331", ended.SpanID)
+	assert.NotContains(t, ended.Attributes[AttributeURLFull], "GB1")
+}
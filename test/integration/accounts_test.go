@@ -227,17 +227,13 @@ func TestAccountsService_ListWithPaginationOutOfBounds(t *testing.T) {
 
 // Helper function.
 func createAccount(t *testing.T) *form3.Account {
-	account := form3.MakeAccount(form3.CreateUUID(), form3.CreateUUID())
-	account.Attributes = &form3.AccountAttributes{
-		Country:                    "DE",
-		BaseCurrency:               "EUR",
-		BankID:                     "12345678",
-		BankIDCode:                 "DEBLZ",
-		CustomerID:                 "XXX-3",
-		Name:                       "Jose2 Sanchez",
-	}
-
-	return account
+	return form3.NewAccountBuilder().
+		OrganisationID(form3.CreateUUID()).
+		Country(form3.CountryDE).
+		BankID("12345678").
+		CustomerID("XXX-3").
+		Name("Jose2 Sanchez").
+		MustBuild()
 }
 
 func populate(t *testing.T, n int) map[string]*form3.Account {
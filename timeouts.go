@@ -0,0 +1,86 @@
+package form3
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Timeouts of operations by method name, used unless OperationTimeouts set one.
+var DefaultOperationTimeouts = map[string]time.Duration{
+	"Create":    5 * time.Second,
+	"List":      15 * time.Second,
+	"ListUnits": 15 * time.Second,
+	"Delete":    3 * time.Second,
+}
+
+// Timeout of operations without a default, e.g. ByID, unless ClientOptions.DefaultTimeout is set.
+const DefaultTimeout = 3 * time.Second
+
+type callOptions struct {
+	timeout    time.Duration
+	timeoutSet bool
+}
+
+// Changes a single call, given as the last arguments of a service method.
+type CallOption func(options *callOptions)
+
+// Timeout of the call instead of the operation default, none when 0.
+// Unlike the default, it also applies when the context has a deadline.
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+		o.timeoutSet = true
+	}
+}
+
+type callOptionsKey struct{}
+
+func withCallOptions(ctx context.Context, opts []CallOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+
+	options := callOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return context.WithValue(ctx, callOptionsKey{}, options)
+}
+
+// Timeout of the operation: the one set for it by name, e.g. accounts.List,
+// or method, e.g. List, then the default of the method, then the client timeout.
+func (c *Client) operationTimeout(operation string) time.Duration {
+	method := operation[strings.LastIndex(operation, ".")+1:]
+	if timeout, ok := c.operationTimeouts[operation]; ok {
+		return timeout
+	}
+	if timeout, ok := c.operationTimeouts[method]; ok {
+		return timeout
+	}
+	if timeout, ok := DefaultOperationTimeouts[method]; ok {
+		return timeout
+	}
+	if c.timeout > 0 {
+		return c.timeout
+	}
+
+	return DefaultTimeout
+}
+
+// Bounds ctx by the call timeout, or by the operation timeout when the caller set no deadline.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := c.operationTimeout(OperationFromContext(ctx))
+	if options, ok := ctx.Value(callOptionsKey{}).(callOptions); ok && options.timeoutSet {
+		timeout = options.timeout
+	} else if _, ok := ctx.Deadline(); ok {
+		timeout = 0
+	}
+
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
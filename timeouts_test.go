@@ -0,0 +1,96 @@
+package form3
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Responds with no content after the delay.
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestOperationTimeout(t *testing.T) {
+	client, _ := NewClient(
+		WithOperationTimeout("List", 20*time.Second),
+		WithOperationTimeout("accounts.List", time.Second),
+		WithOperationTimeout("Cancel", 0),
+	)
+	assert.Equal(t, time.Second, client.operationTimeout("accounts.List"))
+	assert.Equal(t, 20*time.Second, client.operationTimeout("mandates.List"))
+	assert.Equal(t, time.Duration(0), client.operationTimeout("mandates.Cancel"))
+	assert.Equal(t, 5*time.Second, client.operationTimeout("mandates.Create"))
+	assert.Equal(t, DefaultTimeout, client.operationTimeout(""))
+
+	client, _ = NewClient(WithTimeout(time.Minute), WithOperationTimeout("Delete", time.Second))
+	assert.Equal(t, time.Minute, client.operationTimeout("accounts.ByID"))
+	assert.Equal(t, 5*time.Second, client.operationTimeout("accounts.Create"))
+	assert.Equal(t, 15*time.Second, client.operationTimeout("accounts.List"))
+	assert.Equal(t, time.Second, client.operationTimeout("accounts.Delete"))
+
+	// the deprecated milliseconds are a fallback too
+	client = CreateClient(&ClientOptions{Timeout: 1500})
+	assert.Equal(t, 1500*time.Millisecond, client.operationTimeout("accounts.ByID"))
+	assert.Equal(t, 5*time.Second, client.operationTimeout("accounts.Create"))
+
+	client = CreateClient(&ClientOptions{Timeout: 1500, DefaultTimeout: time.Second})
+	assert.Equal(t, time.Second, client.operationTimeout("accounts.ByID"))
+
+	_, err := NewClient(WithOperationTimeout("List", -time.Second))
+	assert.EqualError(t, err, "invalid timeout -1s of List: must not be negative")
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	client, _ := NewClient(WithOperationTimeout("Delete", time.Second))
+	deadline := func(ctx context.Context) time.Duration {
+		ctx, cancel := client.withTimeout(ctx)
+		defer cancel()
+		d, ok := ctx.Deadline()
+		if !ok {
+			return 0
+		}
+		return time.Until(d).Round(time.Second)
+	}
+
+	ctx := withOperation(context.Background(), "accounts.Delete")
+	assert.Equal(t, time.Second, deadline(ctx))
+
+	ctx = withOperation(context.Background(), "accounts.Delete", WithCallTimeout(10*time.Second))
+	assert.Equal(t, 10*time.Second, deadline(ctx))
+
+	ctx = withOperation(context.Background(), "accounts.Delete", WithCallTimeout(0))
+	assert.Equal(t, time.Duration(0), deadline(ctx))
+
+	// deadline of the caller wins over the default, but not over the call timeout
+	parent, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.Equal(t, time.Minute, deadline(withOperation(parent, "accounts.Delete")))
+	assert.Equal(t, 2*time.Second, deadline(withOperation(parent, "accounts.Delete", WithCallTimeout(2*time.Second))))
+}
+
+func TestAccountsService_CallTimeout(t *testing.T) {
+	server := slowServer(50 * time.Millisecond)
+	defer server.Close()
+
+	client, _ := NewClient(WithBaseURL(server.URL), WithOperationTimeout("accounts.Delete", 10*time.Millisecond))
+	service := CreateAccountsService(client)
+
+	_, err := service.Delete(context.Background(), "1", 0)
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+
+	resp, err := service.Delete(context.Background(), "1", 0, WithCallTimeout(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = service.Delete(ctx, "1", 0)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,261 @@
+package form3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Tunes the connection pool and TLS of the transport built when ClientOptions.Transport is nil.
+// Zero values use the defaults noted on the fields. Clients without options other
+// than OnStats share one transport, the others get their own connection pool.
+type TransportOptions struct {
+	// Idle connections kept across all hosts, 100 by default
+	MaxIdleConns int
+
+	// Idle connections kept per host, 32 by default instead of the 2 of http.DefaultTransport
+	MaxIdleConnsPerHost int
+
+	// Connections per host including active ones, unlimited when 0
+	MaxConnsPerHost int
+
+	// How long an idle connection is kept, 90s by default
+	IdleConnTimeout time.Duration
+
+	// Timeouts of dialing and of the TLS handshake, 30s and 10s by default
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+
+	// TCP keep-alive period, 30s by default
+	KeepAlive time.Duration
+
+	// Opens a connection per request
+	DisableKeepAlives bool
+
+	// Uses HTTP/1.1 only, HTTP/2 is attempted over TLS otherwise
+	DisableHTTP2 bool
+
+	// Proxy used for all requests, e.g. http://proxy:3128.
+	// The HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used when empty.
+	ProxyURL string
+
+	// PEM encoded CA certificates trusted on top of the system ones, from a file or given directly
+	CAFile string
+	CAPEM  []byte
+
+	// Client certificate and key of mutual TLS, PEM encoded files
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// Called once the response headers of a request arrive
+	OnStats func(ConnectionStats)
+}
+
+// Timings of a request, zero for phases that did not happen, e.g. on a reused connection.
+type ConnectionStats struct {
+	Method string
+	Host   string
+
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+
+	// From obtaining the connection to the first byte of the response,
+	// excluding the DNS, Connect and TLSHandshake phases
+	FirstByte time.Duration
+
+	// Whether an idle connection of the pool was used
+	Reused bool
+}
+
+// Shared by clients built without ClientOptions.Transport or custom TransportOptions,
+// so they share one connection pool.
+var defaultHTTPTransport, _ = newHTTPTransport(nil)
+
+// Transport used when ClientOptions.Transport is nil, the shared one unless
+// the options change the pool, TLS or proxy settings.
+func transportFor(options *TransportOptions) (*http.Transport, error) {
+	if options == nil {
+		return defaultHTTPTransport, nil
+	}
+
+	// stats are reported by a wrapping transport, the pool can still be shared
+	o := *options
+	o.OnStats = nil
+	if reflect.DeepEqual(o, TransportOptions{}) {
+		return defaultHTTPTransport, nil
+	}
+
+	return newHTTPTransport(options)
+}
+
+// Builds a transport with its own connection pool.
+func newHTTPTransport(options *TransportOptions) (*http.Transport, error) {
+	o := TransportOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.MaxIdleConns == 0 {
+		o.MaxIdleConns = 100
+	}
+	if o.MaxIdleConnsPerHost == 0 {
+		o.MaxIdleConnsPerHost = 32
+	}
+	if o.IdleConnTimeout == 0 {
+		o.IdleConnTimeout = 90 * time.Second
+	}
+	if o.DialTimeout == 0 {
+		o.DialTimeout = 30 * time.Second
+	}
+	if o.TLSHandshakeTimeout == 0 {
+		o.TLSHandshakeTimeout = 10 * time.Second
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = 30 * time.Second
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if o.ProxyURL != "" {
+		proxyURL, err := url.Parse(o.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", o.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   o.DialTimeout,
+			KeepAlive: o.KeepAlive,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     !o.DisableHTTP2,
+		MaxIdleConns:          o.MaxIdleConns,
+		MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       o.MaxConnsPerHost,
+		IdleConnTimeout:       o.IdleConnTimeout,
+		TLSHandshakeTimeout:   o.TLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+		DisableKeepAlives:     o.DisableKeepAlives,
+	}
+	if o.DisableHTTP2 {
+		// a non-nil empty map turns HTTP/2 off
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
+
+func (o *TransportOptions) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	caPEM := o.CAPEM
+	if o.CAFile != "" {
+		data, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA bundle: %v", err)
+		}
+		caPEM = append(append([]byte{}, caPEM...), data...)
+	}
+	if len(caPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("CA bundle: no PEM encoded certificates found")
+		}
+		config.RootCAs = pool
+	}
+
+	if o.ClientCertFile != "" || o.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.ClientCertFile, o.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// Reports ConnectionStats of every request through httptrace.
+type statsTransport struct {
+	next    http.RoundTripper
+	onStats func(ConnectionStats)
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// trace callbacks may run on other goroutines, e.g. DNS lookups
+	var mu sync.Mutex
+	stats := ConnectionStats{Method: req.Method, Host: req.URL.Host}
+	var dnsStart, connectStart, tlsStart, gotConn time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			mu.Lock()
+			dnsStart = time.Now()
+			mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			mu.Lock()
+			stats.DNS = time.Since(dnsStart)
+			mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			mu.Lock()
+			connectStart = time.Now()
+			mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			mu.Lock()
+			stats.Connect = time.Since(connectStart)
+			mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			tlsStart = time.Now()
+			mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mu.Lock()
+			stats.TLSHandshake = time.Since(tlsStart)
+			mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			mu.Lock()
+			stats.Reused = info.Reused
+			gotConn = time.Now()
+			mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			stats.FirstByte = time.Since(gotConn)
+			mu.Unlock()
+		},
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err == nil {
+		mu.Lock()
+		reported := stats
+		mu.Unlock()
+		t.onStats(reported)
+	}
+
+	return resp, err
+}
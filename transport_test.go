@@ -0,0 +1,151 @@
+package form3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPTransport_Defaults(t *testing.T) {
+	transport, err := newHTTPTransport(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, 32, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 10*time.Second, transport.TLSHandshakeTimeout)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Nil(t, transport.TLSNextProto)
+	assert.False(t, transport.DisableKeepAlives)
+
+	transport, err = newHTTPTransport(&TransportOptions{MaxIdleConnsPerHost: 4, DisableHTTP2: true, DisableKeepAlives: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, transport.MaxIdleConnsPerHost)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+	assert.True(t, transport.DisableKeepAlives)
+
+	client, err := NewClient()
+	assert.Nil(t, err)
+	assert.IsType(t, &http.Transport{}, client.httpClient.Transport)
+}
+
+func TestTransportFor_SharesDefault(t *testing.T) {
+	first, _ := NewClient()
+	second, _ := NewClient(WithTransportOptions(&TransportOptions{OnStats: func(ConnectionStats) {}}))
+	assert.Same(t, defaultHTTPTransport, first.httpClient.Transport)
+	assert.Same(t, defaultHTTPTransport, second.httpClient.Transport.(*statsTransport).next)
+
+	custom, _ := NewClient(WithTransportOptions(&TransportOptions{ProxyURL: "http://proxy:3128"}))
+	assert.NotSame(t, defaultHTTPTransport, custom.httpClient.Transport)
+}
+
+func TestNewHTTPTransport_Proxy(t *testing.T) {
+	transport, err := newHTTPTransport(&TransportOptions{ProxyURL: "http://proxy:3128"})
+	assert.Nil(t, err)
+	req, _ := http.NewRequest("GET", "https://api.example.com/v1", nil)
+	proxy, err := transport.Proxy(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://proxy:3128", proxy.String())
+
+	_, err = NewClient(WithTransportOptions(&TransportOptions{ProxyURL: "proxy"}))
+	assert.EqualError(t, err, `invalid proxy URL "proxy"`)
+}
+
+// Writes PEM files of the certificate and key of the test server.
+func writeServerPEM(t *testing.T, server *httptest.Server, dir string) (certFile, keyFile string) {
+	cert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	assert.Nil(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	_ = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600)
+	_ = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600)
+
+	return certFile, keyFile
+}
+
+func TestNewHTTPTransport_TLS(t *testing.T) {
+	var peerCerts int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peerCerts = len(r.TLS.PeerCertificates)
+		fmt.Fprint(w, `{"data": {"id": "1"}}`)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "form3")
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeServerPEM(t, server, dir)
+
+	_, err := NewClient(WithTransportOptions(&TransportOptions{CAFile: filepath.Join(dir, "missing.pem")}))
+	assert.Contains(t, err.Error(), "CA bundle: ")
+
+	_, err = NewClient(WithTransportOptions(&TransportOptions{CAPEM: []byte("x")}))
+	assert.EqualError(t, err, "CA bundle: no PEM encoded certificates found")
+
+	_, err = NewClient(WithTransportOptions(&TransportOptions{ClientCertFile: certFile}))
+	assert.Contains(t, err.Error(), "client certificate: ")
+
+	client, err := NewClient(WithBaseURL(server.URL), WithTransportOptions(&TransportOptions{
+		CAFile:         certFile,
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	}))
+	assert.Nil(t, err)
+
+	account, _, err := CreateAccountsService(client).ByID(context.Background(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", account.ID)
+	assert.Equal(t, 1, peerCerts)
+
+	client, _ = NewClient(WithBaseURL(server.URL))
+	_, _, err = CreateAccountsService(client).ByID(context.Background(), "1")
+	assert.NotNil(t, err)
+}
+
+func TestStatsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"id": "1"}}`)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var stats []ConnectionStats
+	client, err := NewClient(WithBaseURL(server.URL), WithTransportOptions(&TransportOptions{
+		OnStats: func(s ConnectionStats) {
+			mu.Lock()
+			stats = append(stats, s)
+			mu.Unlock()
+		},
+	}))
+	assert.Nil(t, err)
+
+	service := CreateAccountsService(client)
+	for i := 0; i < 2; i++ {
+		_, _, err = service.ByID(context.Background(), "1")
+		assert.Nil(t, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "GET", stats[0].Method)
+	assert.Equal(t, server.Listener.Addr().String(), stats[0].Host)
+	assert.False(t, stats[0].Reused)
+	assert.True(t, stats[0].Connect > 0)
+	assert.True(t, stats[0].FirstByte > 0)
+	assert.True(t, stats[1].Reused)
+	assert.Equal(t, time.Duration(0), stats[1].Connect)
+}
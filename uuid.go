@@ -1,16 +1,18 @@
 package form3
 
 import (
-	"os/exec"
-	"strings"
+	"crypto/rand"
+	"fmt"
 )
 
-const (
-	uuidChars = "abcdefghijkmnpqrstuvwxyz0123456789"
-)
-
-// todo: replace with github.com/google/uuid
+// Creates random (version 4) UUID.
 func CreateUUID() string {
-	out, _ := exec.Command("uuidgen").Output()
-	return strings.TrimRight(string(out), "\n")
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("form3: reading random bytes: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
@@ -11,4 +11,9 @@ func TestCreateUUID(t *testing.T) {
 	v := CreateUUID()
 	c := `[0-9a-z]`
 	assert.Regexp(t, regexp.MustCompile(fmt.Sprintf("^%s{8}-%s{4}-%s{4}-%s{4}-%s{12}$", c, c, c, c, c)), v)
+
+	// version 4, RFC 4122 variant
+	assert.Equal(t, byte('4'), v[14])
+	assert.Contains(t, "89ab", string(v[19]))
+	assert.NotEqual(t, v, CreateUUID())
 }
@@ -0,0 +1,221 @@
+package form3
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWatchInterval = 10 * time.Second
+	defaultWatchPageSize = 100
+)
+
+// Kind of change detected by Watch.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeModified ChangeType = "modified"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+type AccountChange struct {
+	Type ChangeType
+
+	// Current state of the account, the last known one for deleted accounts
+	Account *Account
+}
+
+// Known state of a watched account.
+type WatchState struct {
+	Version    int        `json:"version"`
+	ModifiedOn *time.Time `json:"modified_on,omitempty"`
+}
+
+func (s WatchState) differs(account *Account) bool {
+	if s.Version != account.Version {
+		return true
+	}
+
+	if s.ModifiedOn == nil || account.ModifiedOn == nil {
+		return s.ModifiedOn != account.ModifiedOn
+	}
+
+	return !s.ModifiedOn.Equal(*account.ModifiedOn)
+}
+
+// Snapshot of watched accounts, persist it to resume watching where it stopped.
+type WatchSnapshot struct {
+	Accounts map[string]WatchState `json:"accounts"`
+}
+
+func (s *WatchSnapshot) copy() *WatchSnapshot {
+	c := &WatchSnapshot{Accounts: make(map[string]WatchState, len(s.Accounts))}
+	for id, state := range s.Accounts {
+		c.Accounts[id] = state
+	}
+
+	return c
+}
+
+type WatchOptions struct {
+	// How often accounts are polled, 10s by default
+	Interval time.Duration
+
+	// Size of the pages requested while polling, 100 by default
+	PageSize int
+
+	// Snapshot to resume from, every account is reported as added when nil
+	Snapshot *WatchSnapshot
+
+	// Called with a new snapshot after every completed poll
+	OnSnapshot func(snapshot *WatchSnapshot)
+
+	// Called when a poll fails, the poll is retried on the next tick
+	OnError func(err error)
+}
+
+// Watches accounts for changes by periodically paging through List
+// and comparing id, version and modified_on with the previous poll.
+// The channel is closed once ctx is done.
+func (s *AccountsService) Watch(ctx context.Context, options *WatchOptions) <-chan AccountChange {
+	opts := WatchOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWatchInterval
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultWatchPageSize
+	}
+
+	snapshot := &WatchSnapshot{Accounts: map[string]WatchState{}}
+	if opts.Snapshot != nil {
+		snapshot = opts.Snapshot.copy()
+	}
+
+	changes := make(chan AccountChange)
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			found, err := s.poll(ctx, snapshot, opts.PageSize)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if opts.OnError != nil {
+					opts.OnError(err)
+				}
+			} else {
+				if !emitChanges(ctx, changes, found) {
+					return
+				}
+
+				snapshot = applyChanges(snapshot, found)
+				if opts.OnSnapshot != nil {
+					opts.OnSnapshot(snapshot.copy())
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return changes
+}
+
+// Pages through all accounts and returns changes against the snapshot.
+func (s *AccountsService) poll(ctx context.Context, snapshot *WatchSnapshot, pageSize int) ([]AccountChange, error) {
+	current := make(map[string]*Account)
+
+	for page := 0; ; page++ {
+		options := &ListOptions{Number: strconv.Itoa(page), Size: pageSize}
+		accounts, _, err := s.List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		// accounts created while paging shift others to the next page, so they may repeat
+		for _, account := range accounts {
+			current[account.ID] = account
+		}
+
+		if len(accounts) < pageSize {
+			break
+		}
+	}
+
+	var changes []AccountChange
+	for id, account := range current {
+		state, known := snapshot.Accounts[id]
+		if !known {
+			changes = append(changes, AccountChange{Type: ChangeAdded, Account: account})
+		} else if state.differs(account) {
+			changes = append(changes, AccountChange{Type: ChangeModified, Account: account})
+		}
+	}
+
+	// accounts deleted while paging shift others to the previous page, so a missing
+	// account is confirmed to be gone before being reported as deleted
+	for id, state := range snapshot.Accounts {
+		if _, ok := current[id]; ok {
+			continue
+		}
+
+		account, _, err := s.ByID(ctx, id)
+		if IsNotFound(err) {
+			deleted := &Account{ID: id, Version: state.Version, ModifiedOn: state.ModifiedOn}
+			changes = append(changes, AccountChange{Type: ChangeDeleted, Account: deleted})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if state.differs(account) {
+			changes = append(changes, AccountChange{Type: ChangeModified, Account: account})
+		}
+	}
+
+	return changes, nil
+}
+
+func emitChanges(ctx context.Context, changes chan<- AccountChange, found []AccountChange) bool {
+	for _, change := range found {
+		select {
+		case changes <- change:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+func applyChanges(snapshot *WatchSnapshot, changes []AccountChange) *WatchSnapshot {
+	next := snapshot.copy()
+	for _, change := range changes {
+		if change.Type == ChangeDeleted {
+			delete(next.Accounts, change.Account.ID)
+			continue
+		}
+
+		next.Accounts[change.Account.ID] = WatchState{
+			Version:    change.Account.Version,
+			ModifiedOn: change.Account.ModifiedOn,
+		}
+	}
+
+	return next
+}
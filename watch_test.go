@@ -0,0 +1,201 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// In-memory accounts served by the test server.
+type accountsStore struct {
+	mu       sync.Mutex
+	accounts []*Account
+
+	// called after a page is served, while the lock is held
+	afterPage func(number int)
+}
+
+func (s *accountsStore) register(mux *http.ServeMux) {
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		number, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page[size]"))
+
+		page := []*Account{}
+		for i := number * size; i < (number+1)*size && i < len(s.accounts); i++ {
+			page = append(page, s.accounts[i])
+		}
+
+		_ = json.NewEncoder(w).Encode(body{Data: page})
+		if s.afterPage != nil {
+			s.afterPage(number)
+		}
+	})
+	mux.HandleFunc("/organisation/accounts/", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		id := strings.TrimPrefix(r.URL.Path, "/organisation/accounts/")
+		for _, a := range s.accounts {
+			if a.ID == id {
+				_ = json.NewEncoder(w).Encode(body{Data: a})
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, `{"error_message":"record %s does not exist"}`, id)
+	})
+}
+
+func (s *accountsStore) set(accounts ...*Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts = accounts
+}
+
+func (s *accountsStore) remove(id string) {
+	for i, a := range s.accounts {
+		if a.ID == id {
+			s.accounts = append(s.accounts[:i:i], s.accounts[i+1:]...)
+			return
+		}
+	}
+}
+
+func makeVersionedAccount(id string, version int) *Account {
+	account := MakeAccount(id, "org")
+	account.Version = version
+	return account
+}
+
+func receiveChanges(t *testing.T, changes <-chan AccountChange, n int) map[string]ChangeType {
+	t.Helper()
+	received := make(map[string]ChangeType)
+	for i := 0; i < n; i++ {
+		select {
+		case change := <-changes:
+			received[change.Account.ID] = change.Type
+		case <-time.After(time.Second):
+			t.Fatalf("received %d changes, want %d", len(received), n)
+		}
+	}
+
+	return received
+}
+
+func TestAccountsService_Watch(t *testing.T) {
+	service, mux, _, teardown := setupAccounts()
+	defer teardown()
+
+	store := &accountsStore{}
+	store.register(mux)
+	store.set(makeVersionedAccount("1", 0), makeVersionedAccount("2", 0), makeVersionedAccount("3", 0))
+
+	snapshots := make(chan *WatchSnapshot, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := service.Watch(ctx, &WatchOptions{
+		Interval:   10 * time.Millisecond,
+		PageSize:   2,
+		OnSnapshot: func(snapshot *WatchSnapshot) { snapshots <- snapshot },
+	})
+
+	assert.Equal(t, map[string]ChangeType{"1": ChangeAdded, "2": ChangeAdded, "3": ChangeAdded}, receiveChanges(t, changes, 3))
+
+	store.set(makeVersionedAccount("1", 0), makeVersionedAccount("3", 1), makeVersionedAccount("4", 0))
+	assert.Equal(t, map[string]ChangeType{"2": ChangeDeleted, "3": ChangeModified, "4": ChangeAdded}, receiveChanges(t, changes, 3))
+
+	cancel()
+	for range changes {
+	}
+
+	var last *WatchSnapshot
+	for len(snapshots) > 0 {
+		last = <-snapshots
+	}
+	assert.Equal(t, map[string]WatchState{"1": {Version: 0}, "3": {Version: 1}, "4": {Version: 0}}, last.Accounts)
+}
+
+func TestAccountsService_WatchResumesFromSnapshot(t *testing.T) {
+	service, mux, _, teardown := setupAccounts()
+	defer teardown()
+
+	store := &accountsStore{}
+	store.register(mux)
+	store.set(makeVersionedAccount("1", 0), makeVersionedAccount("2", 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := service.Watch(ctx, &WatchOptions{
+		Interval: 10 * time.Millisecond,
+		Snapshot: &WatchSnapshot{Accounts: map[string]WatchState{"1": {Version: 0}, "2": {Version: 2}}},
+	})
+
+	assert.Equal(t, map[string]ChangeType{"2": ChangeModified}, receiveChanges(t, changes, 1))
+}
+
+func TestAccountsService_WatchCopesWithShiftingPages(t *testing.T) {
+	service, mux, _, teardown := setupAccounts()
+	defer teardown()
+
+	store := &accountsStore{}
+	store.register(mux)
+	store.set(makeVersionedAccount("1", 0), makeVersionedAccount("2", 0), makeVersionedAccount("3", 0), makeVersionedAccount("4", 0))
+
+	// "1" is deleted after the first page is served, so "3" shifts to the first page and is never listed
+	store.afterPage = func(number int) {
+		if number == 0 {
+			store.remove("1")
+			store.afterPage = nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := service.Watch(ctx, &WatchOptions{
+		Interval: 10 * time.Millisecond,
+		PageSize: 2,
+		Snapshot: &WatchSnapshot{Accounts: map[string]WatchState{"1": {}, "2": {}, "3": {}, "4": {}}},
+	})
+
+	assert.Equal(t, map[string]ChangeType{"1": ChangeDeleted}, receiveChanges(t, changes, 1))
+
+	select {
+	case change := <-changes:
+		t.Errorf("unexpected change %s of %s", change.Type, change.Account.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAccountsService_WatchReportsErrors(t *testing.T) {
+	service, mux, _, teardown := setupAccounts()
+	defer teardown()
+
+	mux.HandleFunc("/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.Watch(ctx, &WatchOptions{
+		Interval: 10 * time.Millisecond,
+		OnError:  func(err error) { errs <- err },
+	})
+
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "500 Internal Server Error")
+	case <-time.After(time.Second):
+		t.Fatal("error was not reported")
+	}
+}
@@ -0,0 +1,248 @@
+// Package webhooks receives notifications delivered by subscriptions.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ig-hit/form3"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// Header carrying HMAC-SHA256 signature of the body: sha256=<hex>
+	SignatureHeader = "X-Form3-Signature"
+
+	signaturePrefix = "sha256="
+
+	// Number of delivered event IDs remembered for duplicate suppression.
+	defaultSeenCapacity = 10000
+
+	// Bodies are read before the signature is verified, so their size is bounded.
+	maxBodyBytes = 1 << 20
+)
+
+// Event data not matching its record type.
+var errInvalidData = errors.New("invalid event data")
+
+// Envelope of a delivered notification.
+type Envelope struct {
+	ID             string           `json:"id"`
+	OrganisationID string           `json:"organisation_id"`
+	EventType      form3.EventType  `json:"event_type"`
+	RecordType     form3.RecordType `json:"record_type"`
+	Data           json.RawMessage  `json:"data"`
+}
+
+// Account created, updated or deleted event.
+type AccountEvent struct {
+	ID             string
+	OrganisationID string
+	EventType      form3.EventType
+	Account        *form3.Account
+}
+
+// Handles account events, returned error makes the notification to be redelivered.
+type AccountHandlerFunc func(ctx context.Context, event *AccountEvent) error
+
+// Handler verifies, decodes and dispatches notifications to registered handlers.
+//
+// Delivery is at-least-once: an event ID is remembered only after all its
+// handlers succeeded, so a failed event gets redelivered and a delivered one
+// is acknowledged without dispatching again. A duplicate arriving while the
+// event is being dispatched is refused with 409 Conflict, to be redelivered.
+// Event data that does not decode is refused with 400 Bad Request.
+type Handler struct {
+	secret []byte
+
+	mu              sync.RWMutex
+	accountHandlers map[form3.EventType][]AccountHandlerFunc
+
+	seen *seenSet
+}
+
+// Creates handler verifying signatures with the shared secret.
+func NewHandler(secret []byte) *Handler {
+	return &Handler{
+		secret:          secret,
+		accountHandlers: make(map[form3.EventType][]AccountHandlerFunc),
+		seen:            newSeenSet(defaultSeenCapacity),
+	}
+}
+
+// Registers handler of account events of the given type.
+func (h *Handler) OnAccount(eventType form3.EventType, fn AccountHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.accountHandlers[eventType] = append(h.accountHandlers[eventType], fn)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		if len(body) >= maxBodyBytes {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	if !Verify(h.secret, body, r.Header.Get(SignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	envelope := &Envelope{}
+	if err := json.Unmarshal(body, envelope); err != nil || envelope.ID == "" {
+		http.Error(w, "invalid envelope", http.StatusBadRequest)
+		return
+	}
+
+	reserved, delivered := h.seen.reserve(envelope.ID)
+	if delivered {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !reserved {
+		http.Error(w, "delivery in progress", http.StatusConflict)
+		return
+	}
+	// no-op once delivered, otherwise a failed or panicking handler leaves the event to be redelivered
+	defer h.seen.release(envelope.ID)
+
+	if err := h.dispatch(r.Context(), envelope); err != nil {
+		if errors.Is(err, errInvalidData) {
+			// redelivering the same data cannot succeed
+			http.Error(w, "invalid event data", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "handler failed", http.StatusInternalServerError)
+		return
+	}
+
+	h.seen.add(envelope.ID)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) dispatch(ctx context.Context, envelope *Envelope) error {
+	if envelope.RecordType != form3.RecordTypeAccounts {
+		return nil
+	}
+
+	h.mu.RLock()
+	handlers := h.accountHandlers[envelope.EventType]
+	h.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	account := new(form3.Account)
+	if err := json.Unmarshal(envelope.Data, account); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidData, err)
+	}
+
+	event := &AccountEvent{
+		ID:             envelope.ID,
+		OrganisationID: envelope.OrganisationID,
+		EventType:      envelope.EventType,
+		Account:        account,
+	}
+
+	for _, fn := range handlers {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Signs body with the shared secret, result is the SignatureHeader value.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Reports whether signature is valid for the body.
+func Verify(secret, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Bounded set of delivered event IDs, the oldest IDs are evicted first,
+// along with the IDs of events being dispatched.
+type seenSet struct {
+	mu       sync.Mutex
+	capacity int
+	ids      map[string]struct{}
+	order    []string
+	pending  map[string]struct{}
+}
+
+func newSeenSet(capacity int) *seenSet {
+	return &seenSet{
+		capacity: capacity,
+		ids:      make(map[string]struct{}, capacity),
+		pending:  make(map[string]struct{}),
+	}
+}
+
+// Marks the event as being dispatched unless it is delivered or already being dispatched.
+func (s *seenSet) reserve(id string) (reserved, delivered bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ids[id]; ok {
+		return false, true
+	}
+	if _, ok := s.pending[id]; ok {
+		return false, false
+	}
+
+	s.pending[id] = struct{}{}
+	return true, false
+}
+
+// Releases the reservation of an event that failed, so its redelivery is dispatched.
+func (s *seenSet) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, id)
+}
+
+func (s *seenSet) add(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, id)
+	if _, ok := s.ids[id]; ok {
+		return
+	}
+
+	if len(s.order) >= s.capacity {
+		delete(s.ids, s.order[0])
+		s.order = s.order[1:]
+	}
+
+	s.ids[id] = struct{}{}
+	s.order = append(s.order, id)
+}
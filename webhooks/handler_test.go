@@ -0,0 +1,229 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"github.com/ig-hit/form3"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var secret = []byte("secret")
+
+const accountCreated = `{"id":"e-1","organisation_id":"2","event_type":"created","record_type":"accounts",` +
+	`"data":{"id":"1","organisation_id":"2","type":"accounts","version":0}}`
+
+func setup(handler *Handler) (post func(body, signature string) *http.Response, teardown func()) {
+	server := httptest.NewServer(handler)
+
+	post = func(body, signature string) *http.Response {
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader(body))
+		req.Header.Set(SignatureHeader, signature)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			panic(err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	return post, server.Close
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(accountCreated)
+	assert.True(t, Verify(secret, body, Sign(secret, body)))
+	assert.False(t, Verify([]byte("other"), body, Sign(secret, body)))
+	assert.False(t, Verify(secret, body, strings.TrimPrefix(Sign(secret, body), "sha256=")))
+	assert.False(t, Verify(secret, body, ""))
+}
+
+func TestHandler_DispatchesAccountEvent(t *testing.T) {
+	handler := NewHandler(secret)
+	var received []*AccountEvent
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		received = append(received, event)
+		return nil
+	})
+	handler.OnAccount(form3.EventTypeDeleted, func(ctx context.Context, event *AccountEvent) error {
+		t.Error("deleted handler should not be called")
+		return nil
+	})
+
+	post, teardown := setup(handler)
+	defer teardown()
+
+	resp := post(accountCreated, Sign(secret, []byte(accountCreated)))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, "e-1", received[0].ID)
+	assert.Equal(t, form3.EventTypeCreated, received[0].EventType)
+	assert.Equal(t, form3.MakeAccount("1", "2"), received[0].Account)
+}
+
+func TestHandler_RejectsInvalidSignature(t *testing.T) {
+	handler := NewHandler(secret)
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		t.Error("handler should not be called")
+		return nil
+	})
+
+	post, teardown := setup(handler)
+	defer teardown()
+
+	resp := post(accountCreated, Sign([]byte("other"), []byte(accountCreated)))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandler_RejectsInvalidEnvelope(t *testing.T) {
+	post, teardown := setup(NewHandler(secret))
+	defer teardown()
+
+	resp := post(`{"data":{}}`, Sign(secret, []byte(`{"data":{}}`)))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_RejectsNonPost(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(secret).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandler_SuppressesDuplicates(t *testing.T) {
+	handler := NewHandler(secret)
+	calls := 0
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		calls++
+		return nil
+	})
+
+	post, teardown := setup(handler)
+	defer teardown()
+
+	signature := Sign(secret, []byte(accountCreated))
+	assert.Equal(t, http.StatusOK, post(accountCreated, signature).StatusCode)
+	assert.Equal(t, http.StatusOK, post(accountCreated, signature).StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+func TestHandler_RedeliversFailedEvent(t *testing.T) {
+	handler := NewHandler(secret)
+	calls := 0
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		calls++
+		if calls == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	post, teardown := setup(handler)
+	defer teardown()
+
+	signature := Sign(secret, []byte(accountCreated))
+	assert.Equal(t, http.StatusInternalServerError, post(accountCreated, signature).StatusCode)
+	assert.Equal(t, http.StatusOK, post(accountCreated, signature).StatusCode)
+	assert.Equal(t, 2, calls)
+}
+
+func TestHandler_HidesHandlerError(t *testing.T) {
+	handler := NewHandler(secret)
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		return errors.New("connection to db-1.internal refused")
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(accountCreated))
+	req.Header.Set(SignatureHeader, Sign(secret, []byte(accountCreated)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "handler failed\n", w.Body.String())
+}
+
+func TestHandler_RedeliversAfterPanic(t *testing.T) {
+	handler := NewHandler(secret)
+	calls := 0
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		calls++
+		if calls == 1 {
+			panic("handler bug")
+		}
+		return nil
+	})
+
+	serve := func() int {
+		defer func() { _ = recover() }()
+		req := httptest.NewRequest("POST", "/", strings.NewReader(accountCreated))
+		req.Header.Set(SignatureHeader, Sign(secret, []byte(accountCreated)))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	serve()
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, 2, calls)
+}
+
+func TestHandler_RejectsInvalidEventData(t *testing.T) {
+	handler := NewHandler(secret)
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		return nil
+	})
+
+	post, teardown := setup(handler)
+	defer teardown()
+
+	body := `{"id":"e-1","event_type":"created","record_type":"accounts","data":{"version":"x"}}`
+	assert.Equal(t, http.StatusBadRequest, post(body, Sign(secret, []byte(body))).StatusCode)
+}
+
+func TestHandler_RejectsLargeBody(t *testing.T) {
+	post, teardown := setup(NewHandler(secret))
+	defer teardown()
+
+	body := strings.Repeat("x", maxBodyBytes+1)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(body, Sign(secret, []byte(body))).StatusCode)
+}
+
+func TestHandler_RefusesConcurrentDuplicate(t *testing.T) {
+	handler := NewHandler(secret)
+	started, finish := make(chan struct{}), make(chan struct{})
+	calls := 0
+	handler.OnAccount(form3.EventTypeCreated, func(ctx context.Context, event *AccountEvent) error {
+		calls++
+		close(started)
+		<-finish
+		return nil
+	})
+
+	post, teardown := setup(handler)
+	defer teardown()
+
+	signature := Sign(secret, []byte(accountCreated))
+	first := make(chan int)
+	go func() { first <- post(accountCreated, signature).StatusCode }()
+
+	<-started
+	assert.Equal(t, http.StatusConflict, post(accountCreated, signature).StatusCode)
+	close(finish)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, http.StatusOK, post(accountCreated, signature).StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSeenSet_EvictsOldest(t *testing.T) {
+	seen := newSeenSet(2)
+	seen.add("1")
+	seen.add("2")
+	seen.add("3")
+
+	for id, delivered := range map[string]bool{"1": false, "2": true, "3": true} {
+		_, wasDelivered := seen.reserve(id)
+		assert.Equal(t, delivered, wasDelivered, id)
+	}
+}
@@ -3,31 +3,108 @@ package form3
 import (
 	"net/http"
 	"net/url"
-	"time"
 )
 
 var defaultClientOptions = &ClientOptions{
-	Timeout:      3000,
 	BaseEndpoint: "http://localhost:8080/v1",
 }
 
-func provideHTTPClient(timeout int) *http.Client {
+func provideTransport(options *ClientOptions) http.RoundTripper {
+	transport := options.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if options.TransportOptions != nil && options.TransportOptions.OnStats != nil {
+		transport = &statsTransport{next: transport, onStats: options.TransportOptions.OnStats}
+	}
+
+	// innermost, so credentials are not seen by logging or telemetry
+	if options.Auth != nil && options.Auth.Method != AuthNone {
+		transport = &authTransport{next: transport, auth: options.Auth}
+	}
+
+	if options.RateLimit != nil || len(options.EndpointRateLimits) > 0 {
+		basePath := ""
+		if baseURL, err := url.Parse(options.BaseEndpoint); err == nil {
+			basePath = baseURL.Path
+		}
+		transport = newRateLimitTransport(transport, basePath, options.RateLimit, options.EndpointRateLimits)
+	}
+
+	// outside the rate limiter, so an open breaker fails fast instead of waiting
+	if options.CircuitBreaker != nil {
+		transport = newCircuitBreakerTransport(transport, options.CircuitBreaker)
+	}
+
+	// outside the breaker, so every attempt counts and an open breaker is not retried
+	if options.Retry != nil && options.Retry.MaxAttempts > 1 {
+		transport = newRetryTransport(transport, options.Retry)
+	}
+
+	if options.Telemetry != nil {
+		transport = newTelemetryTransport(transport, options.Telemetry)
+	}
+
+	if options.Logging != nil && options.Logging.Logger != nil {
+		transport = newLoggingTransport(transport, options.Logging)
+	}
+
+	return transport
+}
+
+func provideHTTPClient(options *ClientOptions) *http.Client {
+	// timeouts are applied per operation through the request context
 	return &http.Client{
-		Timeout: time.Duration(timeout) * time.Millisecond,
+		Transport: provideTransport(options),
 	}
 }
 
+// Creates client with the options, the default ones when nil.
+// Options are used as they are, NewClientWithOptions validates them.
+// When the client cannot be built, e.g. the base URL does not parse or the CA file is missing,
+// every request fails with the error.
 func CreateClient(options *ClientOptions) *Client {
 	if options == nil {
 		options = defaultClientOptions
 	}
 
-	baseURL, _ := url.Parse(options.BaseEndpoint)
+	merged := *defaultClientOptions
+	_ = WithClientOptions(options)(&merged)
+
+	client, err := buildClient(merged)
+	if err != nil {
+		merged.Transport = &failingTransport{err: err}
+		if _, parseErr := url.Parse(merged.BaseEndpoint); parseErr != nil {
+			// placeholder, requests fail before reaching it
+			merged.BaseEndpoint = defaultClientOptions.BaseEndpoint
+		}
+		client, _ = buildClient(merged)
+	}
+
+	return client
+}
+
+// Creates client with the options, the default ones when nil, returning an error on invalid options.
+func NewClientWithOptions(options *ClientOptions) (*Client, error) {
+	if options == nil {
+		options = defaultClientOptions
+	}
+
+	return NewClient(WithClientOptions(options))
+}
+
+// Fails every request with the error the transport could not be built with.
+type failingTransport struct {
+	err error
+}
 
-	return &Client{
-		httpClient: provideHTTPClient(options.Timeout),
-		BaseURL:    baseURL,
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
 	}
+
+	return nil, t.err
 }
 
 func CreateAccountsServiceWithOptions(options *ClientOptions) *AccountsService {
@@ -36,6 +113,18 @@ func CreateAccountsServiceWithOptions(options *ClientOptions) *AccountsService {
 	}
 }
 
+// Creates accounts service with the options, returning an error on invalid options.
+func NewAccountsServiceWithOptions(options *ClientOptions) (*AccountsService, error) {
+	client, err := NewClientWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountsService{
+		client: client,
+	}, nil
+}
+
 func CreateAccountsService(client *Client) *AccountsService {
 	if client == nil {
 		client = CreateClient(nil)
@@ -44,3 +133,39 @@ func CreateAccountsService(client *Client) *AccountsService {
 		client: client,
 	}
 }
+
+func CreateMandatesService(client *Client) *MandatesService {
+	if client == nil {
+		client = CreateClient(nil)
+	}
+	return &MandatesService{
+		client: client,
+	}
+}
+
+func CreateDirectDebitsService(client *Client) *DirectDebitsService {
+	if client == nil {
+		client = CreateClient(nil)
+	}
+	return &DirectDebitsService{
+		client: client,
+	}
+}
+
+func CreateOrganisationsService(client *Client) *OrganisationsService {
+	if client == nil {
+		client = CreateClient(nil)
+	}
+	return &OrganisationsService{
+		client: client,
+	}
+}
+
+func CreateSubscriptionsService(client *Client) *SubscriptionsService {
+	if client == nil {
+		client = CreateClient(nil)
+	}
+	return &SubscriptionsService{
+		client: client,
+	}
+}